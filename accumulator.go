@@ -0,0 +1,67 @@
+package thyme
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Accumulator incrementally computes the same totals as AggregateByApp,
+// so that a growing recording can be processed one snapshot at a time.
+// Its state can be saved as a checkpoint and loaded later to resume
+// where it left off.
+type Accumulator struct {
+	// Totals is the total time each application was active.
+	Totals map[string]time.Duration
+
+	// Last is the last snapshot added. The time it covers isn't known
+	// (and so isn't included in Totals) until the next snapshot is
+	// added.
+	Last *Snapshot
+}
+
+// NewAccumulator returns an empty Accumulator.
+func NewAccumulator() *Accumulator {
+	return &Accumulator{Totals: make(map[string]time.Duration)}
+}
+
+// Add adds snap to the accumulated totals. Like in AggregateByApp, of
+// several snapshots with the same time, the last one covers the time
+// until the next snapshot, so a snapshot with the same time as the last
+// one replaces it. Older snapshots are ignored, so a recording can be
+// re-read from the start after loading a checkpoint.
+func (a *Accumulator) Add(snap *Snapshot) {
+	if a.Last != nil {
+		if snap.Time.Before(a.Last.Time) {
+			return
+		}
+		if !snap.Time.Equal(a.Last.Time) {
+			addTotals(a.Totals, AggregateByApp([]*Snapshot{a.Last, snap}))
+		}
+	}
+	a.Last = snap
+}
+
+// addTotals adds the durations of src to dst.
+func addTotals(dst, src map[string]time.Duration) {
+	for k, d := range src {
+		dst[k] += d
+	}
+}
+
+// SaveCheckpoint writes the state of the accumulator to w.
+func (a *Accumulator) SaveCheckpoint(w io.Writer) error {
+	return json.NewEncoder(w).Encode(a)
+}
+
+// LoadCheckpoint reads an accumulator saved by SaveCheckpoint from r.
+func LoadCheckpoint(r io.Reader) (*Accumulator, error) {
+	a := NewAccumulator()
+	if err := json.NewDecoder(r).Decode(a); err != nil {
+		return nil, err
+	}
+	if a.Totals == nil {
+		a.Totals = make(map[string]time.Duration)
+	}
+	return a, nil
+}
@@ -0,0 +1,50 @@
+package thyme
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAccumulator(t *testing.T) {
+	snaps := spans(span{"main.go - GoLand", 3}, span{"general - Acme - Slack", 2})
+	// A snapshot taken at the same time as the next one is replaced by
+	// it.
+	phantom := session(0, "Inbox - Gmail - Google Chrome")[0]
+	phantom.Time = snaps[3].Time
+	snaps = append(snaps[:3], append([]*Snapshot{phantom}, snaps[3:]...)...)
+	want := map[string]time.Duration{"GoLand": 3 * time.Minute, "Slack": 2 * time.Minute}
+	if got := AggregateByApp(snaps); !reflect.DeepEqual(got, want) {
+		t.Fatalf("AggregateByApp = %v, want %v", got, want)
+	}
+
+	a := NewAccumulator()
+	for _, snap := range snaps {
+		a.Add(snap)
+	}
+	if !reflect.DeepEqual(a.Totals, want) {
+		t.Errorf("Totals = %v, want %v", a.Totals, want)
+	}
+
+	// Resuming from a checkpoint and re-reading the recording from the
+	// start ignores the snapshots already added.
+	a = NewAccumulator()
+	for _, snap := range snaps[:4] {
+		a.Add(snap)
+	}
+	var buf bytes.Buffer
+	if err := a.SaveCheckpoint(&buf); err != nil {
+		t.Fatal(err)
+	}
+	a, err := LoadCheckpoint(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, snap := range snaps {
+		a.Add(snap)
+	}
+	if !reflect.DeepEqual(a.Totals, want) {
+		t.Errorf("Totals after resuming from a checkpoint = %v, want %v", a.Totals, want)
+	}
+}
@@ -0,0 +1,736 @@
+package thyme
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// forEachInterval calls fn for every snapshot in snaps along with the
+// interval it covers, which lasts until the time of the next snapshot.
+// The last snapshot has no successor and so covers no time. Intervals
+// that are empty (or negative, if snaps is out of order) are skipped, so
+// of several snapshots with the same time only the last covers any time
+// (see CollapsePhantoms).
+func forEachInterval(snaps []*Snapshot, fn func(snap *Snapshot, start, end time.Time)) {
+	for i := 0; i+1 < len(snaps); i++ {
+		start, end := snaps[i].Time, snaps[i+1].Time
+		if !end.After(start) {
+			continue
+		}
+		fn(snaps[i], start, end)
+	}
+}
+
+// splitInterval calls fn for each part of the interval [start, end) when
+// it is split at boundaries. next returns the first boundary after its
+// argument.
+func splitInterval(start, end time.Time, next func(time.Time) time.Time, fn func(start, end time.Time)) {
+	for start.Before(end) {
+		b := next(start)
+		if b.After(end) {
+			b = end
+		}
+		fn(start, b)
+		start = b
+	}
+}
+
+// nextBucket returns a function that returns the first multiple of
+// bucket after its argument, for use with splitInterval.
+func nextBucket(bucket time.Duration) func(time.Time) time.Time {
+	return func(t time.Time) time.Time {
+		return t.Truncate(bucket).Add(bucket)
+	}
+}
+
+// nextDay returns a function that returns the first midnight in loc
+// after its argument, for use with splitInterval.
+func nextDay(loc *time.Location) func(time.Time) time.Time {
+	return func(t time.Time) time.Time {
+		y, m, d := t.In(loc).Date()
+		return time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+	}
+}
+
+// dayFormat is the layout of the dates keying per-day statistics.
+const dayFormat = "2006-01-02"
+
+// nextHour returns a function that returns the first full hour in loc
+// after its argument, for use with splitInterval.
+func nextHour(loc *time.Location) func(time.Time) time.Time {
+	return func(t time.Time) time.Time {
+		t = t.In(loc)
+		y, m, d := t.Date()
+		return time.Date(y, m, d, t.Hour()+1, 0, 0, 0, loc)
+	}
+}
+
+// nextWeek returns a function that returns the first Monday midnight in
+// loc after its argument, for use with splitInterval.
+func nextWeek(loc *time.Location) func(time.Time) time.Time {
+	return func(t time.Time) time.Time {
+		t = t.In(loc)
+		y, m, d := t.Date()
+		days := (8 - int(t.Weekday())) % 7
+		if days == 0 {
+			days = 7
+		}
+		return time.Date(y, m, d+days, 0, 0, 0, 0, loc)
+	}
+}
+
+// aggregateByPeriod returns the total time each application was active
+// in each period, keyed by key(t) for any time t in the period. Periods
+// are delimited by the boundaries returned by next, and time is split
+// between the periods it spans.
+func aggregateByPeriod(snaps []*Snapshot, next func(time.Time) time.Time, key func(time.Time) string) map[string]map[string]time.Duration {
+	totals := make(map[string]map[string]time.Duration)
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		app := snap.activeApp()
+		if app == "" {
+			return
+		}
+		splitInterval(start, end, next, func(start, end time.Time) {
+			k := key(start)
+			if totals[k] == nil {
+				totals[k] = make(map[string]time.Duration)
+			}
+			totals[k][app] += end.Sub(start)
+		})
+	})
+	return totals
+}
+
+// AggregateByWeek returns the total time each application was active in
+// each ISO week in loc, keyed by week (e.g., "2023-W45"). Time is split
+// between the weeks it spans.
+func AggregateByWeek(snaps []*Snapshot, loc *time.Location) map[string]map[string]time.Duration {
+	return aggregateByPeriod(snaps, nextWeek(loc), func(t time.Time) string {
+		y, w := t.In(loc).ISOWeek()
+		return fmt.Sprintf("%d-W%02d", y, w)
+	})
+}
+
+// AggregateByWeekday returns the total time each application was active
+// on each day of the week in loc. Time is split between the days it
+// spans.
+func AggregateByWeekday(snaps []*Snapshot, loc *time.Location) map[time.Weekday]map[string]time.Duration {
+	totals := make(map[time.Weekday]map[string]time.Duration)
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		app := snap.activeApp()
+		if app == "" {
+			return
+		}
+		splitInterval(start, end, nextDay(loc), func(start, end time.Time) {
+			d := start.In(loc).Weekday()
+			if totals[d] == nil {
+				totals[d] = make(map[string]time.Duration)
+			}
+			totals[d][app] += end.Sub(start)
+		})
+	})
+	return totals
+}
+
+// WeekSplit is the time each application was active on weekdays and on
+// weekends.
+type WeekSplit struct {
+	Weekday, Weekend map[string]time.Duration
+}
+
+// WeekdayVsWeekend returns the total time each application was active on
+// weekdays (Monday to Friday) and on weekends in loc (see
+// AggregateByWeekday).
+func WeekdayVsWeekend(snaps []*Snapshot, loc *time.Location) WeekSplit {
+	split := WeekSplit{
+		Weekday: make(map[string]time.Duration),
+		Weekend: make(map[string]time.Duration),
+	}
+	for d, totals := range AggregateByWeekday(snaps, loc) {
+		dst := split.Weekday
+		if d == time.Saturday || d == time.Sunday {
+			dst = split.Weekend
+		}
+		for app, t := range totals {
+			dst[app] += t
+		}
+	}
+	return split
+}
+
+// EdgeBreakdown is the time each application was active at the start
+// and at the end of days.
+type EdgeBreakdown struct {
+	Start, End map[string]time.Duration
+}
+
+// EdgeOfDayBreakdown returns the total time each application was active
+// during the first and during the last edge of active time of each day
+// in loc (e.g., to estimate time spent warming up and winding down),
+// measured from the first and to the last activity of the day.
+func EdgeOfDayBreakdown(snaps []*Snapshot, loc *time.Location, edge time.Duration) EdgeBreakdown {
+	var pieces []Block
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		app := snap.activeApp()
+		if app == "" {
+			return
+		}
+		splitInterval(start, end, nextDay(loc), func(start, end time.Time) {
+			pieces = append(pieces, Block{Start: start, End: end, App: app})
+		})
+	})
+
+	days := make(map[string]Interval)
+	for _, p := range pieces {
+		day := p.Start.In(loc).Format(dayFormat)
+		span, ok := days[day]
+		if !ok {
+			span.Start = p.Start
+		}
+		span.End = p.End
+		days[day] = span
+	}
+
+	breakdown := EdgeBreakdown{
+		Start: make(map[string]time.Duration),
+		End:   make(map[string]time.Duration),
+	}
+	for _, p := range pieces {
+		span := days[p.Start.In(loc).Format(dayFormat)]
+		if d := overlap(p.Start, p.End, span.Start, span.Start.Add(edge)); d > 0 {
+			breakdown.Start[p.App] += d
+		}
+		if d := overlap(p.Start, p.End, span.End.Add(-edge), span.End); d > 0 {
+			breakdown.End[p.App] += d
+		}
+	}
+	return breakdown
+}
+
+// overlap returns the length of the intersection of the intervals
+// [start1, end1) and [start2, end2), which is negative if they don't
+// intersect.
+func overlap(start1, end1, start2, end2 time.Time) time.Duration {
+	if start2.After(start1) {
+		start1 = start2
+	}
+	if end2.Before(end1) {
+		end1 = end2
+	}
+	return end1.Sub(start1)
+}
+
+// AggregateOptions controls how AggregateByAppWith attributes time to
+// applications.
+type AggregateOptions struct {
+	// UnifyBrowsers attributes the time of every browser to the single
+	// key BrowserCategory rather than to the individual browsers.
+	UnifyBrowsers bool
+
+	// IdleCredit, if set, attributes each period of idle time to the
+	// active application if the user was idle for less than IdleCredit
+	// in total (e.g., while reading), rather than dropping it. Longer
+	// periods of idle time are dropped entirely.
+	IdleCredit time.Duration
+
+	// TrimLeading, if set, discards the first TrimLeading of each
+	// contiguous visit to an application, so that brief, accidental
+	// visits count for little or nothing.
+	TrimLeading time.Duration
+}
+
+// appKey returns the key the active application of snap is aggregated
+// under, or "" if its time isn't attributed to any application. credited
+// tells whether the idle time of snap is credited (see creditedIdle).
+func (opts AggregateOptions) appKey(snap *Snapshot, credited bool) string {
+	app := snap.activeApp()
+	if app == "" && credited {
+		app = snap.activeAppID()
+	}
+	if app != "" && opts.UnifyBrowsers && snap.ActiveWindow().Info().IsBrowser() {
+		return BrowserCategory
+	}
+	return app
+}
+
+// creditedIdle returns the idle snapshots of snaps whose time is credited
+// to the active application according to IdleCredit: those of every run
+// of consecutive idle snapshots during which the user was idle for less
+// than IdleCredit in total, from their last input until the next snapshot
+// that isn't idle.
+func (opts AggregateOptions) creditedIdle(snaps []*Snapshot) map[*Snapshot]bool {
+	credited := make(map[*Snapshot]bool)
+	if opts.IdleCredit <= 0 {
+		return credited
+	}
+	for i := 0; i < len(snaps); {
+		if !snaps[i].IsIdle() {
+			i++
+			continue
+		}
+		j := i
+		for j < len(snaps) && snaps[j].IsIdle() {
+			j++
+		}
+		end := snaps[len(snaps)-1].Time
+		if j < len(snaps) {
+			end = snaps[j].Time
+		}
+		if end.Sub(snaps[i].Time.Add(-snaps[i].Idle)) < opts.IdleCredit {
+			for _, snap := range snaps[i:j] {
+				credited[snap] = true
+			}
+		}
+		i = j
+	}
+	return credited
+}
+
+// AggregateByApp returns the total time each application was active.
+// Idle time and time without an active application window aren't
+// attributed to any application.
+func AggregateByApp(snaps []*Snapshot) map[string]time.Duration {
+	return AggregateByAppWith(snaps, AggregateOptions{})
+}
+
+// AggregateByAppWith is like AggregateByApp, but attributes time
+// according to opts.
+func AggregateByAppWith(snaps []*Snapshot, opts AggregateOptions) map[string]time.Duration {
+	totals := make(map[string]time.Duration)
+	credited := opts.creditedIdle(snaps)
+	var visit Block
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		app := opts.appKey(snap, credited[snap])
+		if app == "" {
+			return
+		}
+		if app != visit.App || !visit.End.Equal(start) {
+			visit = Block{Start: start, App: app}
+		}
+		visit.End = end
+		if credited := visit.Start.Add(opts.TrimLeading); credited.After(start) {
+			start = credited
+		}
+		if start.Before(end) {
+			totals[app] += end.Sub(start)
+		}
+	})
+	return totals
+}
+
+// activeCategory returns the category of the active application of
+// the snapshot, or "" if its time isn't attributed to any application. Time
+// spent sharing the screen is attributed to PresentingCategory.
+func (s Snapshot) activeCategory() string {
+	if s.activeApp() == "" {
+		return ""
+	}
+	if s.IsScreenSharing() {
+		return PresentingCategory
+	}
+	return s.ActiveWindow().Info().Category()
+}
+
+// AggregateByCategory returns the total time spent in each category of
+// applications (see Winfo.Category), with time spent sharing the screen
+// attributed to PresentingCategory.
+func AggregateByCategory(snaps []*Snapshot) map[string]time.Duration {
+	totals := make(map[string]time.Duration)
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		if c := snap.activeCategory(); c != "" {
+			totals[c] += end.Sub(start)
+		}
+	})
+	return totals
+}
+
+// CommunicationsBreakdown returns the total time applications of
+// CommunicationCategory were active, along with the time of each of
+// them.
+func CommunicationsBreakdown(snaps []*Snapshot) (total time.Duration, byApp map[string]time.Duration) {
+	byApp = make(map[string]time.Duration)
+	for app, t := range AggregateByApp(snaps) {
+		if appCategory(app) == CommunicationCategory {
+			byApp[app] = t
+			total += t
+		}
+	}
+	return total, byApp
+}
+
+// AggregateBy returns the total time the active window was labeled with
+// each label, as determined by labelFunc (e.g., (*Window).GroupKey).
+func AggregateBy(snaps []*Snapshot, labelFunc func(*Window) string) map[string]time.Duration {
+	totals := make(map[string]time.Duration)
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		if snap.activeApp() == "" {
+			return
+		}
+		totals[labelFunc(snap.ActiveWindow())] += end.Sub(start)
+	})
+	return totals
+}
+
+// TotalActive returns the total time any application was active.
+func TotalActive(snaps []*Snapshot) time.Duration {
+	var total time.Duration
+	for _, d := range AggregateByApp(snaps) {
+		total += d
+	}
+	return total
+}
+
+// AppEntropy returns the Shannon entropy, in bits, of the distribution
+// of active time across applications (see AggregateByApp): 0 if all the
+// time was spent in a single application, and higher the more evenly the
+// time was spread across more applications. It returns 0 if no
+// application was active.
+func AppEntropy(snaps []*Snapshot) float64 {
+	totals := AggregateByApp(snaps)
+	var total time.Duration
+	for _, t := range totals {
+		total += t
+	}
+	entropy := 0.0
+	for _, t := range totals {
+		if t > 0 {
+			p := float64(t) / float64(total)
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+// AppTime is the time attributed to an application.
+type AppTime struct {
+	App  string
+	Time time.Duration
+}
+
+// sortByTime returns the entries of totals ordered by decreasing time.
+// Ties are broken by application name.
+func sortByTime(totals map[string]time.Duration) []AppTime {
+	sorted := make([]AppTime, 0, len(totals))
+	for app, d := range totals {
+		sorted = append(sorted, AppTime{App: app, Time: d})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Time != sorted[j].Time {
+			return sorted[i].Time > sorted[j].Time
+		}
+		return sorted[i].App < sorted[j].App
+	})
+	return sorted
+}
+
+// NoActiveWindow is the label given to time during which no application
+// window was active (e.g., the desktop was focused or all windows were
+// minimized).
+const NoActiveWindow = "(no active window)"
+
+// NoActiveRatio returns the fraction of the recorded time during which no
+// application window was active. It returns 0 if snaps covers no time.
+func NoActiveRatio(snaps []*Snapshot) float64 {
+	var none, total time.Duration
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		total += end.Sub(start)
+		if snap.ActiveWindow() == nil {
+			none += end.Sub(start)
+		}
+	})
+	if total == 0 {
+		return 0
+	}
+	return float64(none) / float64(total)
+}
+
+// TimeToFirstFocus returns the time from the first snapshot to the first
+// snapshot in which an application of the category makerCategory (e.g.,
+// DevelopmentCategory) was active. It returns 0 if no such application
+// was ever active.
+func TimeToFirstFocus(snaps []*Snapshot, makerCategory string) time.Duration {
+	for _, snap := range snaps {
+		if snap.activeApp() != "" && snap.ActiveWindow().Info().Category() == makerCategory {
+			return snap.Time.Sub(snaps[0].Time)
+		}
+	}
+	return 0
+}
+
+// titleChangeWindow is how recently the title of a window must have
+// changed for it to be considered being edited rather than read.
+const titleChangeWindow = time.Minute
+
+// EditingReading is the time an application was active, split by
+// whether it was likely being edited or read.
+type EditingReading struct {
+	Editing time.Duration
+	Reading time.Duration
+}
+
+// EditingVsReading estimates, for each application, how much of its
+// active time was spent editing rather than reading. Time is considered
+// editing if the active window's name (which often includes the file
+// being edited or the cursor position) changed within the last minute,
+// and reading otherwise. This is only a rough proxy.
+func EditingVsReading(snaps []*Snapshot) map[string]EditingReading {
+	split := make(map[string]EditingReading)
+	lastName := make(map[string]string)
+	lastChange := make(map[string]time.Time)
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		app := snap.activeApp()
+		if app == "" {
+			return
+		}
+		name := snap.ActiveWindow().Name
+		if prev, ok := lastName[app]; ok && prev != name {
+			lastChange[app] = start
+		}
+		lastName[app] = name
+
+		er := split[app]
+		if t, ok := lastChange[app]; ok && start.Sub(t) < titleChangeWindow {
+			er.Editing += end.Sub(start)
+		} else {
+			er.Reading += end.Sub(start)
+		}
+		split[app] = er
+	})
+	return split
+}
+
+// EngagementByApp returns, for each application, how many times the
+// name of the active window changed while the application stayed active
+// (e.g., while typing or moving between files), a rough measure of
+// active engagement as opposed to an application being focused while
+// the user is away.
+func EngagementByApp(snaps []*Snapshot) map[string]int {
+	changes := make(map[string]int)
+	var lastApp, lastName string
+	for _, snap := range CollapsePhantoms(snaps) {
+		app := snap.activeApp()
+		if app == "" {
+			lastApp = ""
+			continue
+		}
+		name := snap.ActiveWindow().Name
+		if app == lastApp && name != lastName {
+			changes[app]++
+		}
+		lastApp, lastName = app, name
+	}
+	return changes
+}
+
+// SupportingTime returns, for each active application, how long each
+// other application had a window visible alongside it (e.g.,
+// documentation kept open while coding). The result maps active
+// applications to supporting applications to time.
+func SupportingTime(snaps []*Snapshot) map[string]map[string]time.Duration {
+	support := make(map[string]map[string]time.Duration)
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		active := snap.activeApp()
+		if active == "" {
+			return
+		}
+		counted := make(map[string]bool)
+		for _, w := range snap.VisibleWindows() {
+			if w.IsSystem() {
+				continue
+			}
+			app := appID(w)
+			if app == active || counted[app] {
+				continue
+			}
+			counted[app] = true
+			if support[active] == nil {
+				support[active] = make(map[string]time.Duration)
+			}
+			support[active][app] += end.Sub(start)
+		}
+	})
+	return support
+}
+
+// AppPresence returns, for each application, the total time it had any
+// window open, whether or not the window was active or visible (e.g.,
+// how long a music player was running in the background). Idle time
+// counts toward presence, since the windows stay open.
+func AppPresence(snaps []*Snapshot) map[string]time.Duration {
+	totals := make(map[string]time.Duration)
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		counted := make(map[string]bool)
+		for _, w := range snap.Windows {
+			if w.IsSystem() {
+				continue
+			}
+			app := appID(w)
+			if counted[app] {
+				continue
+			}
+			counted[app] = true
+			totals[app] += end.Sub(start)
+		}
+	})
+	return totals
+}
+
+// ForegroundRatio returns, for each application that had a window open,
+// the fraction of its presence time (see AppPresence) during which it
+// was active (see AggregateByApp). Applications that are left running
+// but rarely used have a ratio close to 0.
+func ForegroundRatio(snaps []*Snapshot) map[string]float64 {
+	active := AggregateByApp(snaps)
+	ratios := make(map[string]float64)
+	for app, presence := range AppPresence(snaps) {
+		if presence > 0 {
+			ratios[app] = float64(active[app]) / float64(presence)
+		}
+	}
+	return ratios
+}
+
+// parseClock parses a time of day in the form "15:04" as the time since
+// midnight.
+func parseClock(clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// FocusDebt returns the time spent in applications of commCategory
+// (e.g., CommunicationCategory) during the declared daily focus hours
+// from start to end in loc, given in the form "15:04". It returns 0 if
+// start or end can't be parsed.
+func FocusDebt(snaps []*Snapshot, loc *time.Location, start, end string, commCategory string) time.Duration {
+	from, err := parseClock(start)
+	if err != nil {
+		return 0
+	}
+	to, err := parseClock(end)
+	if err != nil {
+		return 0
+	}
+	var debt time.Duration
+	forEachInterval(snaps, func(snap *Snapshot, s, e time.Time) {
+		if snap.activeCategory() != commCategory {
+			return
+		}
+		splitInterval(s, e, nextDay(loc), func(s, e time.Time) {
+			y, m, d := s.In(loc).Date()
+			midnight := time.Date(y, m, d, 0, 0, 0, 0, loc)
+			focusStart, focusEnd := midnight.Add(from), midnight.Add(to)
+			if s.After(focusStart) {
+				focusStart = s
+			}
+			if e.Before(focusEnd) {
+				focusEnd = e
+			}
+			if focusEnd.After(focusStart) {
+				debt += focusEnd.Sub(focusStart)
+			}
+		})
+	})
+	return debt
+}
+
+// Screen states reported by AggregateByScreenState.
+const (
+	FullscreenState   = "Fullscreen"
+	EmptyDesktopState = "Empty desktop"
+	WindowedState     = "Windowed"
+)
+
+// AggregateByScreenState returns the total time spent in each screen
+// state: with a single application in focus (FullscreenState, see
+// Snapshot.IsFullscreenFocus), with no windows open at all
+// (EmptyDesktopState), or otherwise (WindowedState).
+func AggregateByScreenState(snaps []*Snapshot) map[string]time.Duration {
+	totals := make(map[string]time.Duration)
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		state := WindowedState
+		switch {
+		case snap.IsEmptyDesktop():
+			state = EmptyDesktopState
+		case snap.IsFullscreenFocus():
+			state = FullscreenState
+		}
+		totals[state] += end.Sub(start)
+	})
+	return totals
+}
+
+// aggregateByHour returns the total time each application was active in
+// each hour of the day in loc, summed over all days.
+func aggregateByHour(snaps []*Snapshot, loc *time.Location) [24]map[string]time.Duration {
+	var totals [24]map[string]time.Duration
+	for h := range totals {
+		totals[h] = make(map[string]time.Duration)
+	}
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		app := snap.activeApp()
+		if app == "" {
+			return
+		}
+		splitInterval(start, end, nextHour(loc), func(start, end time.Time) {
+			totals[start.In(loc).Hour()][app] += end.Sub(start)
+		})
+	})
+	return totals
+}
+
+// DistinctAppsByHour returns the number of distinct applications active
+// in each hour of the day in loc. High counts indicate scattered hours.
+func DistinctAppsByHour(snaps []*Snapshot, loc *time.Location) [24]int {
+	var counts [24]int
+	for h, totals := range aggregateByHour(snaps, loc) {
+		counts[h] = len(totals)
+	}
+	return counts
+}
+
+// Dominance is the application used most during a period, along with
+// its share of the active time of the period.
+type Dominance struct {
+	App   string
+	Share float64
+}
+
+// HourlyDominance returns, for each hour of the day in loc, the
+// application that was active the longest and its share of the time
+// any application was active during the hour. A low share indicates a
+// scattered hour. Hours without active time have the zero Dominance.
+func HourlyDominance(snaps []*Snapshot, loc *time.Location) [24]Dominance {
+	var dominance [24]Dominance
+	for h, totals := range aggregateByHour(snaps, loc) {
+		if len(totals) == 0 {
+			continue
+		}
+		var total time.Duration
+		for _, t := range totals {
+			total += t
+		}
+		top := sortByTime(totals)[0]
+		dominance[h] = Dominance{App: top.App, Share: float64(top.Time) / float64(total)}
+	}
+	return dominance
+}
+
+// ActiveAt returns the metadata of the window that was active at time t,
+// or nil if t lies outside the recording or no application window was
+// active at t. snaps must be ordered by time.
+func ActiveAt(snaps []*Snapshot, t time.Time) *Winfo {
+	// i is the first snapshot after t, so snaps[i-1] covers t.
+	i := sort.Search(len(snaps), func(i int) bool { return snaps[i].Time.After(t) })
+	if i == 0 || i == len(snaps) {
+		return nil
+	}
+	if w := snaps[i-1].ActiveWindow(); w != nil {
+		return w.Info()
+	}
+	return nil
+}
@@ -0,0 +1,347 @@
+package thyme
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIdleCredit(t *testing.T) {
+	snaps := spans(span{"main.go - GoLand", 50})
+	// A short idle period, 7 minutes in total, and a long one.
+	snaps[10].Idle, snaps[11].Idle = 5*time.Minute, 6*time.Minute
+	for i := 20; i < 40; i++ {
+		snaps[i].Idle = IdleThreshold + time.Duration(i-20)*time.Minute
+	}
+	tests := []struct {
+		credit time.Duration
+		want   time.Duration
+	}{
+		{0, 28 * time.Minute},
+		{10 * time.Minute, 30 * time.Minute},
+		{time.Hour, 50 * time.Minute},
+	}
+	for _, test := range tests {
+		want := map[string]time.Duration{"GoLand": test.want}
+		if got := AggregateByAppWith(snaps, AggregateOptions{IdleCredit: test.credit}); !reflect.DeepEqual(got, want) {
+			t.Errorf("with IdleCredit %s, totals = %v, want %v", test.credit, got, want)
+		}
+	}
+}
+
+func TestNoActiveRatio(t *testing.T) {
+	snaps := spans(span{"main.go - GoLand", 30}, span{"Inbox - Gmail - Google Chrome", 10})
+	// The windows are still listed, but none of them is active.
+	for _, snap := range snaps[30:40] {
+		snap.Active = 0
+	}
+	if got := NoActiveRatio(snaps); math.Abs(got-0.25) > 1e-9 {
+		t.Errorf("NoActiveRatio = %g, want 0.25", got)
+	}
+	if got := NoActiveRatio(nil); got != 0 {
+		t.Errorf("NoActiveRatio of no snapshots = %g, want 0", got)
+	}
+}
+
+func TestUnifyBrowsers(t *testing.T) {
+	snaps := spans(span{"Inbox - Gmail - Google Chrome", 10}, span{"Page — Mozilla Firefox", 15}, span{"main.go - GoLand", 5})
+	tests := []struct {
+		opts AggregateOptions
+		want map[string]time.Duration
+	}{
+		{AggregateOptions{}, map[string]time.Duration{"Google Chrome": 10 * time.Minute, "Firefox": 15 * time.Minute, "GoLand": 5 * time.Minute}},
+		{AggregateOptions{UnifyBrowsers: true}, map[string]time.Duration{BrowserCategory: 25 * time.Minute, "GoLand": 5 * time.Minute}},
+	}
+	for _, test := range tests {
+		if got := AggregateByAppWith(snaps, test.opts); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("AggregateByAppWith(%+v) = %v, want %v", test.opts, got, test.want)
+		}
+	}
+}
+
+func TestTimeToFirstFocus(t *testing.T) {
+	snaps := spans(span{"Inbox - Gmail - Google Chrome", 7}, span{"general - Acme - Slack", 3}, span{"main.go - GoLand", 10})
+	if got := TimeToFirstFocus(snaps, DevelopmentCategory); got != 10*time.Minute {
+		t.Errorf("TimeToFirstFocus = %s, want 10m", got)
+	}
+	if got := TimeToFirstFocus(snaps[:10], DevelopmentCategory); got != 0 {
+		t.Errorf("TimeToFirstFocus without an editor = %s, want 0", got)
+	}
+}
+
+func TestAggregateByWeek(t *testing.T) {
+	// From 23:30 on Sunday to 00:30 on Monday UTC.
+	snaps := spans(span{"main.go - GoLand", 60})
+	for _, snap := range snaps {
+		snap.Time = snap.Time.Add(-9*time.Hour - 30*time.Minute)
+	}
+	tests := []struct {
+		loc  *time.Location
+		want map[string]map[string]time.Duration
+	}{
+		{time.UTC, map[string]map[string]time.Duration{
+			"2026-W09": {"GoLand": 30 * time.Minute},
+			"2026-W10": {"GoLand": 30 * time.Minute},
+		}},
+		// It is already Monday two hours east of UTC.
+		{time.FixedZone("UTC+2", 2*60*60), map[string]map[string]time.Duration{
+			"2026-W10": {"GoLand": time.Hour},
+		}},
+	}
+	for _, test := range tests {
+		if got := AggregateByWeek(snaps, test.loc); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("AggregateByWeek in %s = %v, want %v", test.loc, got, test.want)
+		}
+	}
+}
+
+func TestEditingVsReading(t *testing.T) {
+	var names []string
+	for i := 0; i < 20; i++ {
+		names = append(names, fmt.Sprintf("file%d.go - GoLand", i))
+	}
+	for i := 0; i < 20; i++ {
+		names = append(names, "paper.pdf - Evince")
+	}
+	snaps := session(30*time.Second, append(names, "")...)
+	// The first interval of the editor precedes any title change.
+	want := map[string]EditingReading{
+		"GoLand": {Editing: 9*time.Minute + 30*time.Second, Reading: 30 * time.Second},
+		"Evince": {Reading: 10 * time.Minute},
+	}
+	if got := EditingVsReading(snaps); !reflect.DeepEqual(got, want) {
+		t.Errorf("EditingVsReading = %+v, want %+v", got, want)
+	}
+}
+
+func TestSupportingTime(t *testing.T) {
+	snaps := spans(span{"main.go - GoLand", 10}, span{"Inbox - Gmail - Google Chrome", 5})
+	for i, snap := range snaps[:15] {
+		snap.Windows = []*Window{{ID: 1, Name: "main.go - GoLand"}, {ID: 2, Name: "Inbox - Gmail - Google Chrome"}}
+		snap.Visible = []int64{1, 2}
+		if i >= 10 {
+			snap.Active, snap.Visible = 2, []int64{2}
+		}
+	}
+	want := map[string]map[string]time.Duration{"GoLand": {"Google Chrome": 10 * time.Minute}}
+	if got := SupportingTime(snaps); !reflect.DeepEqual(got, want) {
+		t.Errorf("SupportingTime = %v, want %v", got, want)
+	}
+}
+
+func TestFocusDebt(t *testing.T) {
+	// Slack is active from 9:00 to 9:05, 10:30 to 10:50, and 11:50 to
+	// 12:20.
+	snaps := spans(span{"general - Acme - Slack", 5}, span{"main.go - GoLand", 85}, span{"general - Acme - Slack", 20}, span{"main.go - GoLand", 60}, span{"general - Acme - Slack", 30})
+	if got := FocusDebt(snaps, time.UTC, "10:00", "12:00", CommunicationCategory); got != 30*time.Minute {
+		t.Errorf("FocusDebt = %s, want 30m", got)
+	}
+	if got := FocusDebt(snaps, time.UTC, "10", "12:00", CommunicationCategory); got != 0 {
+		t.Errorf("FocusDebt with invalid focus hours = %s, want 0", got)
+	}
+}
+
+func TestAggregateByScreenState(t *testing.T) {
+	snaps := spans(span{"main.go - GoLand", 10}, span{"main.go - GoLand", 5}, span{"", 5}, span{"main.go - GoLand", 5})
+	for _, snap := range snaps[:10] {
+		// Nothing is visible, as in the overview or a fullscreen
+		// application.
+		snap.Visible = []int64{}
+	}
+	// The visible windows of snaps[10:15] are unknown.
+	for _, snap := range snaps[20:25] {
+		snap.Windows = append(snap.Windows, &Window{ID: 2, Name: "Inbox - Gmail - Google Chrome"})
+		snap.Visible = []int64{1, 2}
+	}
+	want := map[string]time.Duration{FullscreenState: 10 * time.Minute, WindowedState: 10 * time.Minute, EmptyDesktopState: 5 * time.Minute}
+	if got := AggregateByScreenState(snaps); !reflect.DeepEqual(got, want) {
+		t.Errorf("AggregateByScreenState = %v, want %v", got, want)
+	}
+}
+
+func TestDistinctAppsByHour(t *testing.T) {
+	snaps := spans(span{"main.go - GoLand", 20}, span{"general - Acme - Slack", 10}, span{"Inbox - Gmail - Google Chrome", 20}, span{"main.go - GoLand", 40})
+	var want [24]int
+	want[9], want[10] = 3, 1
+	if got := DistinctAppsByHour(snaps, time.UTC); got != want {
+		t.Errorf("DistinctAppsByHour = %v, want %v", got, want)
+	}
+}
+
+func TestActiveAt(t *testing.T) {
+	snaps := spans(span{"main.go - GoLand", 10}, span{"Inbox - Gmail - Google Chrome", 10})
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{testStart.Add(12*time.Minute + 30*time.Second), "Google Chrome"},
+		{testStart.Add(10 * time.Minute), "Google Chrome"},
+		{testStart.Add(9*time.Minute + 59*time.Second), "GoLand"},
+		// Outside the recording.
+		{testStart.Add(-time.Second), ""},
+		{testStart.Add(20 * time.Minute), ""},
+	}
+	for _, test := range tests {
+		got := ""
+		if info := ActiveAt(snaps, test.t); info != nil {
+			got = info.App
+		}
+		if got != test.want {
+			t.Errorf("ActiveAt(%s) = %q, want %q", test.t.Format("15:04:05"), got, test.want)
+		}
+	}
+}
+
+// testPresenceRecording returns a recording in which an editor is active
+// for 20 minutes, the last 5 of them idle, while a music player runs in
+// the background for the first 15 minutes.
+func testPresenceRecording() []*Snapshot {
+	snaps := spans(span{"main.go - GoLand", 20})
+	for i, snap := range snaps[:20] {
+		snap.Windows = append(snap.Windows, &Window{ID: 2, Name: "Desktop"})
+		if i < 15 {
+			snap.Windows = append(snap.Windows, &Window{ID: 3, Name: "Song - Spotify"}, &Window{ID: 4, Name: "Other song - Spotify"})
+		} else {
+			snap.Idle = IdleThreshold
+		}
+	}
+	return snaps
+}
+
+func TestAppPresence(t *testing.T) {
+	// Spotify is never active or visible, and idle time counts.
+	want := map[string]time.Duration{"GoLand": 20 * time.Minute, "Spotify": 15 * time.Minute}
+	if got := AppPresence(testPresenceRecording()); !reflect.DeepEqual(got, want) {
+		t.Errorf("AppPresence = %v, want %v", got, want)
+	}
+}
+
+func TestForegroundRatio(t *testing.T) {
+	snaps := testPresenceRecording()
+	// Spotify is focused for a minute.
+	snaps[0].Active = 3
+	got := ForegroundRatio(snaps)
+	want := map[string]float64{"GoLand": 14.0 / 20, "Spotify": 1.0 / 15}
+	if len(got) != len(want) {
+		t.Fatalf("ForegroundRatio = %v, want %v", got, want)
+	}
+	for app, ratio := range want {
+		if math.Abs(got[app]-ratio) > 1e-9 {
+			t.Errorf("ForegroundRatio of %s = %g, want %g", app, got[app], ratio)
+		}
+	}
+	if got["Spotify"] > 0.1 {
+		t.Errorf("ForegroundRatio of the background app = %g, want it low", got["Spotify"])
+	}
+}
+
+func TestCommunicationsBreakdown(t *testing.T) {
+	snaps := spans(span{"general - Acme - Slack", 10}, span{"main.go - GoLand", 30}, span{"Standup - Zoom", 15}, span{"general - Acme - Slack", 5})
+	total, byApp := CommunicationsBreakdown(snaps)
+	want := map[string]time.Duration{"Slack": 15 * time.Minute, "Zoom": 15 * time.Minute}
+	if total != 30*time.Minute || !reflect.DeepEqual(byApp, want) {
+		t.Errorf("CommunicationsBreakdown = %s, %v, want 30m, %v", total, byApp, want)
+	}
+}
+
+func TestHourlyDominance(t *testing.T) {
+	// 9:00 is dominated by GoLand, while 10:00 is scattered.
+	snaps := spans(span{"main.go - GoLand", 54}, span{"general - Acme - Slack", 6},
+		span{"main.go - GoLand", 15}, span{"Inbox - Gmail - Google Chrome", 20}, span{"general - Acme - Slack", 15}, span{"Standup - Zoom", 10})
+	got := HourlyDominance(snaps, time.UTC)
+	want := map[int]Dominance{9: {App: "GoLand", Share: 0.9}, 10: {App: "Google Chrome", Share: 1.0 / 3}}
+	for h, d := range got {
+		w := want[h]
+		if d.App != w.App || math.Abs(d.Share-w.Share) > 1e-9 {
+			t.Errorf("HourlyDominance at %d:00 = %+v, want %+v", h, d, w)
+		}
+	}
+}
+
+func TestAppEntropy(t *testing.T) {
+	tests := []struct {
+		name  string
+		snaps []*Snapshot
+		want  float64
+	}{
+		{"empty", nil, 0},
+		{"single app", spans(span{"main.go - GoLand", 60}), 0},
+		{"uniform four apps", spans(span{"main.go - GoLand", 15}, span{"Inbox - Gmail - Google Chrome", 15}, span{"general - Acme - Slack", 15}, span{"Standup - Zoom", 15}), 2},
+	}
+	for _, test := range tests {
+		if got := AppEntropy(test.snaps); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%s: AppEntropy = %g bits, want %g", test.name, got, test.want)
+		}
+	}
+}
+
+func TestTrimLeading(t *testing.T) {
+	snaps := spans(span{"general - Acme - Slack", 1}, span{"main.go - GoLand", 1}, span{"general - Acme - Slack", 1}, span{"main.go - GoLand", 30}, span{"general - Acme - Slack", 3})
+	// Visits of at most 2 minutes count for nothing, and the others lose
+	// their first 2 minutes.
+	want := map[string]time.Duration{"GoLand": 28 * time.Minute, "Slack": time.Minute}
+	if got := AggregateByAppWith(snaps, AggregateOptions{TrimLeading: 2 * time.Minute}); !reflect.DeepEqual(got, want) {
+		t.Errorf("AggregateByAppWith TrimLeading 2m = %v, want %v", got, want)
+	}
+}
+
+func TestAggregateByWeekday(t *testing.T) {
+	// From 23:30 on Monday to 01:00 on Tuesday.
+	snaps := spans(span{"main.go - GoLand", 60}, span{"Inbox - Gmail - Google Chrome", 30})
+	for _, snap := range snaps {
+		snap.Time = snap.Time.Add(14*time.Hour + 30*time.Minute)
+	}
+	want := map[time.Weekday]map[string]time.Duration{
+		time.Monday:  {"GoLand": 30 * time.Minute},
+		time.Tuesday: {"GoLand": 30 * time.Minute, "Google Chrome": 30 * time.Minute},
+	}
+	if got := AggregateByWeekday(snaps, time.UTC); !reflect.DeepEqual(got, want) {
+		t.Errorf("AggregateByWeekday = %v, want %v", got, want)
+	}
+}
+
+func TestWeekdayVsWeekend(t *testing.T) {
+	// From 23:00 on Friday to 01:00 on Saturday.
+	snaps := spans(span{"main.go - GoLand", 90}, span{"Inbox - Gmail - Google Chrome", 30})
+	for _, snap := range snaps {
+		snap.Time = snap.Time.Add(4*24*time.Hour + 14*time.Hour)
+	}
+	want := WeekSplit{
+		Weekday: map[string]time.Duration{"GoLand": time.Hour},
+		Weekend: map[string]time.Duration{"GoLand": 30 * time.Minute, "Google Chrome": 30 * time.Minute},
+	}
+	if got := WeekdayVsWeekend(snaps, time.UTC); !reflect.DeepEqual(got, want) {
+		t.Errorf("WeekdayVsWeekend = %+v, want %+v", got, want)
+	}
+}
+
+func TestEdgeOfDayBreakdown(t *testing.T) {
+	// The first activity of the day is at 9:15 and the last ends at 11:55.
+	snaps := spans(span{"", 15}, span{"Inbox - Gmail - Google Chrome", 20}, span{"main.go - GoLand", 120}, span{"general - Acme - Slack", 20})
+	want := EdgeBreakdown{
+		Start: map[string]time.Duration{"Google Chrome": 20 * time.Minute, "GoLand": 10 * time.Minute},
+		End:   map[string]time.Duration{"GoLand": 10 * time.Minute, "Slack": 20 * time.Minute},
+	}
+	if got := EdgeOfDayBreakdown(snaps, time.UTC, 30*time.Minute); !reflect.DeepEqual(got, want) {
+		t.Errorf("EdgeOfDayBreakdown = %+v, want %+v", got, want)
+	}
+}
+
+func TestEngagementByApp(t *testing.T) {
+	var names []string
+	for i := 0; i < 20; i++ {
+		names = append(names, fmt.Sprintf("file%d.go - GoLand", i))
+	}
+	for i := 0; i < 20; i++ {
+		names = append(names, "paper.pdf - Evince")
+	}
+	// Returning to the editor with another title isn't a change while it
+	// was active.
+	names = append(names, "main.go - GoLand", "")
+	got := EngagementByApp(session(30*time.Second, names...))
+	if got["GoLand"] != 19 || got["Evince"] != 0 {
+		t.Errorf("EngagementByApp = %v, want 19 title changes in GoLand and none in Evince", got)
+	}
+}
@@ -0,0 +1,345 @@
+package thyme
+
+import (
+	"bufio"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// binaryMagic identifies the binary encoding written by WriteBinary,
+// followed by its version. Version 2 added Window.Type, version 3
+// Window.Collapsed, and version 4 compressed everything after the
+// version; ReadBinary still reads the earlier versions.
+const (
+	binaryMagic   = "THYB"
+	binaryVersion = 4
+
+	// maxBinaryStringLen bounds the strings ReadBinary accepts, so that
+	// corrupt input can't cause huge allocations.
+	maxBinaryStringLen = 1 << 20
+)
+
+// WriteBinary writes snaps to w in a compact binary encoding suitable
+// for archiving long recordings. Integers are varint-encoded, snapshot
+// times are encoded as the delta from the previous snapshot, and every
+// distinct string (e.g., a window name) is written only once and then
+// referred to by index. Since consecutive snapshots mostly list the same
+// windows, the encoding that follows the header is also compressed with
+// DEFLATE. Use ReadBinary to decode it.
+func WriteBinary(w io.Writer, snaps []*Snapshot) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(binaryMagic)
+	var buf [binary.MaxVarintLen64]byte
+	bw.Write(buf[:binary.PutUvarint(buf[:], binaryVersion)])
+
+	zw, err := flate.NewWriter(bw, flate.DefaultCompression)
+	if err != nil {
+		return err
+	}
+	if err := writeBinaryRecords(zw, snaps); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// writeBinaryRecords writes the uncompressed encoding of snaps that
+// follows the header of the binary encoding.
+func writeBinaryRecords(w io.Writer, snaps []*Snapshot) error {
+	e := &binaryEncoder{w: bufio.NewWriter(w), strings: make(map[string]uint64)}
+	e.uvarint(uint64(len(snaps)))
+	for _, snap := range snaps {
+		e.snapshot(snap)
+	}
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// ReadBinary reads snapshots written by WriteBinary from r. Snapshot
+// times are returned in the local time zone.
+func ReadBinary(r io.Reader) ([]*Snapshot, error) {
+	d := &binaryDecoder{r: bufio.NewReader(r)}
+	magic := make([]byte, len(binaryMagic))
+	if _, err := io.ReadFull(d.r, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != binaryMagic {
+		return nil, errors.New("not a binary thyme recording")
+	}
+	d.version = d.uvarint()
+	if d.err == nil && (d.version < 1 || d.version > binaryVersion) {
+		return nil, fmt.Errorf("unsupported binary thyme recording version %d", d.version)
+	}
+	if d.version >= 4 {
+		zr := flate.NewReader(d.r)
+		defer zr.Close()
+		d.r = bufio.NewReader(zr)
+	}
+	n := d.uvarint()
+	var snaps []*Snapshot
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		snaps = append(snaps, d.snapshot())
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return snaps, nil
+}
+
+// binaryEncoder writes the binary encoding. Once a write fails, err is
+// set and all further writes are no-ops.
+type binaryEncoder struct {
+	w       *bufio.Writer
+	buf     [binary.MaxVarintLen64]byte
+	strings map[string]uint64
+	last    int64
+	err     error
+}
+
+func (e *binaryEncoder) bytes(b []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+}
+
+func (e *binaryEncoder) uvarint(n uint64) {
+	e.bytes(e.buf[:binary.PutUvarint(e.buf[:], n)])
+}
+
+func (e *binaryEncoder) varint(n int64) {
+	e.bytes(e.buf[:binary.PutVarint(e.buf[:], n)])
+}
+
+func (e *binaryEncoder) bool(b bool) {
+	if b {
+		e.uvarint(1)
+	} else {
+		e.uvarint(0)
+	}
+}
+
+// string writes s as a reference to a previously written string, or, if
+// s hasn't been written before, as 0 followed by the string itself.
+func (e *binaryEncoder) string(s string) {
+	if i, ok := e.strings[s]; ok {
+		e.uvarint(i)
+		return
+	}
+	e.strings[s] = uint64(len(e.strings) + 1)
+	e.uvarint(0)
+	e.uvarint(uint64(len(s)))
+	e.bytes([]byte(s))
+}
+
+func (e *binaryEncoder) snapshot(s *Snapshot) {
+	t := s.Time.UnixNano()
+	e.varint(t - e.last)
+	e.last = t
+
+	e.uvarint(uint64(len(s.Windows)))
+	for _, w := range s.Windows {
+		e.varint(w.ID)
+		e.varint(w.Desktop)
+		e.string(w.Name)
+		e.string(w.Class)
+		e.string(w.Output)
+		e.string(w.Type)
+		e.uvarint(uint64(w.Collapsed))
+		e.opacity(w.Opacity)
+		e.bool(w.Geometry != nil)
+		if w.Geometry != nil {
+			e.rect(*w.Geometry)
+		}
+	}
+
+	e.varint(s.Active)
+	// Distinguish a nil Visible (unknown) from an empty one.
+	if s.Visible == nil {
+		e.uvarint(0)
+	} else {
+		e.uvarint(uint64(len(s.Visible)) + 1)
+	}
+	for _, v := range s.Visible {
+		e.varint(v)
+	}
+
+	e.varint(s.Desktop)
+	e.varint(int64(s.Idle))
+	e.uvarint(uint64(len(s.Outputs)))
+	for _, o := range s.Outputs {
+		e.string(o.Name)
+		e.rect(o.Rect)
+		e.bool(o.Primary)
+	}
+}
+
+// opacity writes o, which is almost always 0 (unknown) or 1 (opaque),
+// as 0 or 1, or as 2 followed by its IEEE 754 bits otherwise.
+func (e *binaryEncoder) opacity(o float64) {
+	switch o {
+	case 0, 1:
+		e.uvarint(uint64(o))
+	default:
+		e.uvarint(2)
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(o))
+		e.bytes(b[:])
+	}
+}
+
+func (e *binaryEncoder) rect(r Rect) {
+	e.varint(int64(r.X))
+	e.varint(int64(r.Y))
+	e.varint(int64(r.Width))
+	e.varint(int64(r.Height))
+}
+
+// binaryDecoder reads the binary encoding. Once a read fails, err is set
+// and all further reads return zero values.
+type binaryDecoder struct {
+	r       *bufio.Reader
+	version uint64
+	strings []string
+	last    int64
+	err     error
+}
+
+func (d *binaryDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	n, err := binary.ReadUvarint(d.r)
+	d.setErr(err)
+	return n
+}
+
+func (d *binaryDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	n, err := binary.ReadVarint(d.r)
+	d.setErr(err)
+	return n
+}
+
+// setErr records err, treating a premature end of input as corruption.
+func (d *binaryDecoder) setErr(err error) {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+func (d *binaryDecoder) bool() bool {
+	return d.uvarint() != 0
+}
+
+func (d *binaryDecoder) string() string {
+	i := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+	if i > 0 {
+		if i > uint64(len(d.strings)) {
+			d.setErr(fmt.Errorf("invalid string reference %d", i))
+			return ""
+		}
+		return d.strings[i-1]
+	}
+	n := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+	if n > maxBinaryStringLen {
+		d.setErr(fmt.Errorf("string length %d exceeds maximum", n))
+		return ""
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		d.setErr(err)
+		return ""
+	}
+	d.strings = append(d.strings, string(b))
+	return string(b)
+}
+
+func (d *binaryDecoder) snapshot() *Snapshot {
+	d.last += d.varint()
+	s := &Snapshot{Time: time.Unix(0, d.last)}
+
+	n := d.uvarint()
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		w := &Window{
+			ID:      d.varint(),
+			Desktop: d.varint(),
+			Name:    d.string(),
+			Class:   d.string(),
+			Output:  d.string(),
+		}
+		if d.version >= 2 {
+			w.Type = d.string()
+		}
+		if d.version >= 3 {
+			w.Collapsed = int(d.uvarint())
+		}
+		w.Opacity = d.opacity()
+		if d.bool() {
+			r := d.rect()
+			w.Geometry = &r
+		}
+		s.Windows = append(s.Windows, w)
+	}
+
+	s.Active = d.varint()
+	if n := d.uvarint(); n > 0 {
+		s.Visible = []int64{}
+		for i := uint64(1); i < n && d.err == nil; i++ {
+			s.Visible = append(s.Visible, d.varint())
+		}
+	}
+
+	s.Desktop = d.varint()
+	s.Idle = time.Duration(d.varint())
+	n = d.uvarint()
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		s.Outputs = append(s.Outputs, Output{Name: d.string(), Rect: d.rect(), Primary: d.bool()})
+	}
+	return s
+}
+
+func (d *binaryDecoder) opacity() float64 {
+	switch tag := d.uvarint(); tag {
+	case 0, 1:
+		return float64(tag)
+	case 2:
+		var b [8]byte
+		if _, err := io.ReadFull(d.r, b[:]); err != nil {
+			d.setErr(err)
+			return 0
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b[:]))
+	default:
+		d.setErr(fmt.Errorf("invalid opacity tag %d", tag))
+		return 0
+	}
+}
+
+func (d *binaryDecoder) rect() Rect {
+	return Rect{
+		X:      int(d.varint()),
+		Y:      int(d.varint()),
+		Width:  int(d.varint()),
+		Height: int(d.varint()),
+	}
+}
@@ -0,0 +1,95 @@
+package thyme
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testRecording returns a recording of an hour of work, with a snapshot
+// every 5 seconds of a handful of windows whose names change now and
+// then.
+func testRecording() []*Snapshot {
+	var snaps []*Snapshot
+	for i := 0; i < 720; i++ {
+		snap := &Snapshot{
+			Time: testStart.Add(time.Duration(i) * 5 * time.Second),
+			Windows: []*Window{
+				{ID: 0x3a00004, Name: fmt.Sprintf("file%d.go - thyme - Visual Studio Code", i/60), Class: "Code", Geometry: &Rect{X: 0, Y: 0, Width: 1920, Height: 1080}, Output: "DP-1", Type: NormalWindowType},
+				{ID: 0x4200001, Name: fmt.Sprintf("Issue #%d - GitHub - Google Chrome", i/90), Class: "Google-chrome", Geometry: &Rect{X: 1920, Y: 0, Width: 1920, Height: 1080}, Output: "HDMI-1", Type: NormalWindowType},
+				{ID: 0x5000007, Name: "~/code/thyme - Terminal", Class: "Gnome-terminal", Opacity: 0.9, Type: NormalWindowType},
+				{ID: 0x5800002, Name: "general - Acme - Slack", Class: "Slack", Desktop: 1, Type: NormalWindowType},
+				{ID: 0x6400001, Name: "Top Panel", Desktop: -1, Type: "dock", Collapsed: 2},
+			},
+			Visible: []int64{0x3a00004, 0x4200001},
+			Desktop: 0,
+		}
+		switch i % 40 {
+		case 0, 1, 2, 3:
+			snap.Active = 0x5800002
+		case 4, 5, 6, 7, 8, 9:
+			snap.Active = 0x4200001
+		default:
+			snap.Active = 0x3a00004
+		}
+		if i >= 600 {
+			snap.Idle = time.Duration(i-600) * 5 * time.Second
+		}
+		if i == 0 {
+			snap.Outputs = []Output{{Name: "DP-1", Primary: true, Rect: Rect{Width: 1920, Height: 1080}}, {Name: "HDMI-1", Rect: Rect{X: 1920, Width: 1920, Height: 1080}}}
+		}
+		snaps = append(snaps, snap)
+	}
+	return snaps
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	snaps := testRecording()
+	var buf bytes.Buffer
+	if err := WriteBinary(&buf, snaps); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadBinary(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(snaps) {
+		t.Fatalf("ReadBinary read %d snapshots, want %d", len(got), len(snaps))
+	}
+	for i, snap := range got {
+		// Times are decoded in the local time zone.
+		if !snap.Time.Equal(snaps[i].Time) {
+			t.Errorf("snapshot %d has time %s, want %s", i, snap.Time, snaps[i].Time)
+		}
+		snap.Time = snaps[i].Time
+		if !reflect.DeepEqual(snap, snaps[i]) {
+			t.Fatalf("snapshot %d = %s, want %s", i, snap.Print(), snaps[i].Print())
+		}
+	}
+}
+
+func TestBinarySize(t *testing.T) {
+	snaps := testRecording()
+	var jsonl bytes.Buffer
+	z := gzip.NewWriter(&jsonl)
+	enc := json.NewEncoder(z)
+	for _, snap := range snaps {
+		if err := enc.Encode(snap); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var bin bytes.Buffer
+	if err := WriteBinary(&bin, snaps); err != nil {
+		t.Fatal(err)
+	}
+	if 2*bin.Len() > jsonl.Len() {
+		t.Errorf("binary encoding is %d bytes, want at most half the %d bytes of gzipped JSONL", bin.Len(), jsonl.Len())
+	}
+}
@@ -0,0 +1,184 @@
+package thyme
+
+import "time"
+
+// Block is a contiguous period of time during which a single application
+// was active.
+type Block struct {
+	Start time.Time
+	End   time.Time
+	App   string
+}
+
+// Duration returns the length of the block.
+func (b Block) Duration() time.Duration {
+	return b.End.Sub(b.Start)
+}
+
+// FocusBlocks returns the blocks of time during which the same
+// application stayed active, in order. Idle time and time without an
+// active application window end the current block.
+func FocusBlocks(snaps []*Snapshot) []Block {
+	var blocks []Block
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		app := snap.activeApp()
+		if app == "" {
+			return
+		}
+		if n := len(blocks); n > 0 && blocks[n-1].App == app && blocks[n-1].End.Equal(start) {
+			blocks[n-1].End = end
+			return
+		}
+		blocks = append(blocks, Block{Start: start, End: end, App: app})
+	})
+	return blocks
+}
+
+// longestBlock returns the longest of blocks, or the zero Block if there
+// are none.
+func longestBlock(blocks []Block) Block {
+	var longest Block
+	for _, b := range blocks {
+		if b.Duration() > longest.Duration() {
+			longest = b
+		}
+	}
+	return longest
+}
+
+// FocusBlockByHour returns the mean length of the focus blocks (see
+// FocusBlocks) starting in each hour of the day in loc. Hours in which
+// no block starts are zero.
+func FocusBlockByHour(snaps []*Snapshot, loc *time.Location) [24]time.Duration {
+	var total [24]time.Duration
+	var count [24]int
+	for _, b := range FocusBlocks(snaps) {
+		h := b.Start.In(loc).Hour()
+		total[h] += b.Duration()
+		count[h]++
+	}
+	var mean [24]time.Duration
+	for h := range mean {
+		if count[h] > 0 {
+			mean[h] = total[h] / time.Duration(count[h])
+		}
+	}
+	return mean
+}
+
+// DeepWorkByDay returns, for each day in loc (keyed by its date, e.g.,
+// "2006-01-02"), the total time spent in focus blocks (see FocusBlocks)
+// lasting at least minBlock. Blocks spanning midnight are split between
+// the days they span.
+func DeepWorkByDay(snaps []*Snapshot, loc *time.Location, minBlock time.Duration) map[string]time.Duration {
+	totals := make(map[string]time.Duration)
+	for _, b := range FocusBlocks(snaps) {
+		if b.Duration() < minBlock {
+			continue
+		}
+		splitInterval(b.Start, b.End, nextDay(loc), func(start, end time.Time) {
+			totals[start.In(loc).Format(dayFormat)] += end.Sub(start)
+		})
+	}
+	return totals
+}
+
+// DeepWorkWindow returns the longest period of the recording during
+// which applications were continuously active and the rate of
+// application switches stayed at or below maxSwitchesPerHour. Like in
+// FocusBlocks, idle time and time without an active application window
+// end a period. The App of the returned block is the application that
+// was active the longest during the period. The zero Block is returned
+// if no application was active.
+//
+// Every period between two snapshots is considered, so this takes time
+// quadratic in the number of snapshots.
+func DeepWorkWindow(snaps []*Snapshot, maxSwitchesPerHour float64) Block {
+	apps := make([]string, len(snaps))
+	for i, snap := range snaps {
+		apps[i] = snap.activeApp()
+	}
+
+	var best Block
+	var bestStart, bestEnd int
+	for i := 0; i+1 < len(snaps); i++ {
+		if apps[i] == "" {
+			continue
+		}
+		switches := 0
+		for j := i; j+1 < len(snaps) && apps[j] != ""; j++ {
+			if j > i && apps[j] != apps[j-1] {
+				switches++
+			}
+			d := snaps[j+1].Time.Sub(snaps[i].Time)
+			if d <= best.Duration() {
+				continue
+			}
+			if float64(switches)/d.Hours() <= maxSwitchesPerHour {
+				best = Block{Start: snaps[i].Time, End: snaps[j+1].Time}
+				bestStart, bestEnd = i, j+1
+			}
+		}
+	}
+	if best.Duration() > 0 {
+		if top := sortByTime(AggregateByApp(snaps[bestStart : bestEnd+1])); len(top) > 0 {
+			best.App = top[0].App
+		}
+	}
+	return best
+}
+
+// Fragmentation describes how fragmented the use of an application is.
+type Fragmentation struct {
+	// Total is the total time the application was active.
+	Total time.Duration
+
+	// Visits is the number of focus blocks of the application.
+	Visits int
+
+	// AvgVisit is the mean length of the visits.
+	AvgVisit time.Duration
+}
+
+// AppFragmentation returns the fragmentation of each application's
+// use, based on its focus blocks (see FocusBlocks). Applications with
+// many short visits are the most fragmenting.
+func AppFragmentation(snaps []*Snapshot) map[string]Fragmentation {
+	frags := make(map[string]Fragmentation)
+	for _, b := range FocusBlocks(snaps) {
+		f := frags[b.App]
+		f.Total += b.Duration()
+		f.Visits++
+		frags[b.App] = f
+	}
+	for app, f := range frags {
+		f.AvgVisit = f.Total / time.Duration(f.Visits)
+		frags[app] = f
+	}
+	return frags
+}
+
+// LongestUninterruptedByComms returns the duration and bounds of the
+// longest period during which applications were active continuously
+// without any application of CommunicationCategory becoming active.
+// Idle time and time without an active application window also end the
+// period. It returns zeros if no other application was active.
+func LongestUninterruptedByComms(snaps []*Snapshot) (time.Duration, time.Time, time.Time) {
+	var longest, current Block
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		app := snap.activeApp()
+		if app == "" || appCategory(app) == CommunicationCategory {
+			current = Block{}
+			return
+		}
+		if current.End.Equal(start) && !current.Start.IsZero() {
+			current.End = end
+		} else {
+			current = Block{Start: start, End: end}
+		}
+		if current.Duration() > longest.Duration() {
+			longest = current
+		}
+	})
+	return longest.Duration(), longest.Start, longest.End
+}
@@ -0,0 +1,107 @@
+package thyme
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDeepWorkWindow(t *testing.T) {
+	idle := spans(span{"main.go - GoLand", 40})
+	for _, snap := range idle[15:30] {
+		snap.Idle = IdleThreshold
+	}
+	at := func(m int) time.Time { return testStart.Add(time.Duration(m) * time.Minute) }
+	tests := []struct {
+		name               string
+		snaps              []*Snapshot
+		maxSwitchesPerHour float64
+		want               Block
+	}{{
+		name:  "no active window",
+		snaps: spans(span{"main.go - GoLand", 20}, span{"", 30}, span{"main.go - GoLand", 10}),
+		want:  Block{Start: at(0), End: at(20), App: "GoLand"},
+	}, {
+		name:  "idle",
+		snaps: idle,
+		want:  Block{Start: at(0), End: at(15), App: "GoLand"},
+	}, {
+		name:               "switches within the rate",
+		snaps:              spans(span{"main.go - GoLand", 30}, span{"general - Acme - Slack", 1}, span{"main.go - GoLand", 30}),
+		maxSwitchesPerHour: 2,
+		want:               Block{Start: at(0), End: at(61), App: "GoLand"},
+	}, {
+		name:               "switches above the rate",
+		snaps:              spans(span{"main.go - GoLand", 30}, span{"general - Acme - Slack", 1}, span{"main.go - GoLand", 30}),
+		maxSwitchesPerHour: 1,
+		want:               Block{Start: at(0), End: at(30), App: "GoLand"},
+	}, {
+		name:  "nothing active",
+		snaps: spans(span{"", 10}),
+	}}
+	for _, test := range tests {
+		if got := DeepWorkWindow(test.snaps, test.maxSwitchesPerHour); got != test.want {
+			t.Errorf("%s: DeepWorkWindow = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestFocusBlockByHour(t *testing.T) {
+	// The browser block starts at 9:50 and ends at 10:20, so it counts
+	// towards 9:00.
+	snaps := spans(span{"main.go - GoLand", 40}, span{"general - Acme - Slack", 10}, span{"Inbox - Gmail - Google Chrome", 30}, span{"main.go - GoLand", 20})
+	var want [24]time.Duration
+	want[9] = (40 + 10 + 30) * time.Minute / 3
+	want[10] = 20 * time.Minute
+	if got := FocusBlockByHour(snaps, time.UTC); got != want {
+		t.Errorf("FocusBlockByHour = %v, want %v", got, want)
+	}
+}
+
+func TestAppFragmentation(t *testing.T) {
+	snaps := spans(span{"general - Acme - Slack", 2}, span{"main.go - GoLand", 10}, span{"general - Acme - Slack", 4}, span{"main.go - GoLand", 10}, span{"general - Acme - Slack", 3})
+	want := map[string]Fragmentation{
+		"Slack":  {Total: 9 * time.Minute, Visits: 3, AvgVisit: 3 * time.Minute},
+		"GoLand": {Total: 20 * time.Minute, Visits: 2, AvgVisit: 10 * time.Minute},
+	}
+	if got := AppFragmentation(snaps); !reflect.DeepEqual(got, want) {
+		t.Errorf("AppFragmentation = %+v, want %+v", got, want)
+	}
+}
+
+func TestDeepWorkByDay(t *testing.T) {
+	snaps := spans(span{"main.go - GoLand", 30}, span{"general - Acme - Slack", 3}, span{"Inbox - Gmail - Google Chrome", 10}, span{"general - Acme - Slack", 2}, span{"main.go - GoLand", 30}, span{"Inbox - Gmail - Google Chrome", 5})
+	want := map[string]time.Duration{"2026-03-02": time.Hour}
+	if got := DeepWorkByDay(snaps, time.UTC, 25*time.Minute); !reflect.DeepEqual(got, want) {
+		t.Errorf("DeepWorkByDay = %v, want %v", got, want)
+	}
+
+	// Starting at 23:45, the first block is split between the days.
+	for _, snap := range snaps {
+		snap.Time = snap.Time.Add(14*time.Hour + 45*time.Minute)
+	}
+	want = map[string]time.Duration{"2026-03-02": 15 * time.Minute, "2026-03-03": 45 * time.Minute}
+	if got := DeepWorkByDay(snaps, time.UTC, 25*time.Minute); !reflect.DeepEqual(got, want) {
+		t.Errorf("DeepWorkByDay across midnight = %v, want %v", got, want)
+	}
+}
+
+func TestLongestUninterruptedByComms(t *testing.T) {
+	at := func(m int) time.Time { return testStart.Add(time.Duration(m) * time.Minute) }
+	tests := []struct {
+		snaps      []*Snapshot
+		want       time.Duration
+		start, end time.Time
+	}{
+		// Switching between other applications doesn't break the stretch.
+		{spans(span{"main.go - GoLand", 20}, span{"Inbox - Gmail - Google Chrome", 20}, span{"general - Acme - Slack", 1}, span{"main.go - GoLand", 25}), 40 * time.Minute, at(0), at(40)},
+		{spans(span{"main.go - GoLand", 25}, span{"general - Acme - Slack", 1}, span{"main.go - GoLand", 30}), 30 * time.Minute, at(26), at(56)},
+		{spans(span{"general - Acme - Slack", 10}), 0, time.Time{}, time.Time{}},
+	}
+	for i, test := range tests {
+		d, start, end := LongestUninterruptedByComms(test.snaps)
+		if d != test.want || !start.Equal(test.start) || !end.Equal(test.end) {
+			t.Errorf("%d: LongestUninterruptedByComms = %s from %s to %s, want %s from %s to %s", i, d, start, end, test.want, test.start, test.end)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package thyme
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// AggCache is an in-memory cache of aggregation results, so that
+// computing the same statistics over the same recording repeatedly
+// (e.g., for several report formats) doesn't recompute them. It is safe
+// for concurrent use.
+type AggCache struct {
+	mu      sync.Mutex
+	entries map[string]map[string]time.Duration
+}
+
+// NewAggCache returns an empty cache.
+func NewAggCache() *AggCache {
+	return &AggCache{entries: make(map[string]map[string]time.Duration)}
+}
+
+// Get returns a copy of the totals cached under key, if any.
+func (c *AggCache) Get(key string) (map[string]time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	totals, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	return copyTotals(totals), true
+}
+
+// Put caches a copy of totals under key.
+func (c *AggCache) Put(key string, totals map[string]time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = copyTotals(totals)
+}
+
+func copyTotals(totals map[string]time.Duration) map[string]time.Duration {
+	c := make(map[string]time.Duration, len(totals))
+	for k, v := range totals {
+		c[k] = v
+	}
+	return c
+}
+
+// WithCache returns an aggregation function that returns the result of
+// agg from cache if agg was already computed over the same snapshots,
+// and otherwise computes and caches it. Since functions can't be
+// compared, name identifies agg in the cache and must be unique to it
+// (e.g., "AggregateByApp"). Snapshots are identified by a hash of their
+// contents, so modified snapshots are recomputed; changes to the
+// parsing options and registries, however, don't invalidate the cache.
+func WithCache(cache *AggCache, name string, agg func([]*Snapshot) map[string]time.Duration) func([]*Snapshot) map[string]time.Duration {
+	return func(snaps []*Snapshot) map[string]time.Duration {
+		key, err := cacheKey(name, snaps)
+		if err != nil {
+			return agg(snaps)
+		}
+		if totals, ok := cache.Get(key); ok {
+			return totals
+		}
+		totals := agg(snaps)
+		cache.Put(key, totals)
+		return totals
+	}
+}
+
+// cacheKey returns the key of the result of the aggregation name over
+// snaps, which is based on a hash of their binary encoding. The
+// encoding is hashed uncompressed, which is much faster.
+func cacheKey(name string, snaps []*Snapshot) (string, error) {
+	h := sha256.New()
+	if err := writeBinaryRecords(h, snaps); err != nil {
+		return "", err
+	}
+	return name + ":" + hex.EncodeToString(h.Sum(nil)), nil
+}
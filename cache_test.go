@@ -0,0 +1,93 @@
+package thyme
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWithCache(t *testing.T) {
+	calls := 0
+	agg := WithCache(NewAggCache(), "AggregateByApp", func(snaps []*Snapshot) map[string]time.Duration {
+		calls++
+		return AggregateByApp(snaps)
+	})
+
+	snaps := session(time.Minute, "main.go - GoLand", "main.go - GoLand", "general - Acme - Slack", "")
+	want := map[string]time.Duration{"GoLand": 2 * time.Minute, "Slack": time.Minute}
+	for i := 1; i <= 2; i++ {
+		if got := agg(snaps); !reflect.DeepEqual(got, want) {
+			t.Errorf("call %d returned %v, want %v", i, got, want)
+		}
+		if calls != 1 {
+			t.Errorf("after call %d, the aggregation ran %d times, want 1", i, calls)
+		}
+	}
+
+	// Modified snapshots are recomputed.
+	snaps[1].Windows[0].Name = "general - Acme - Slack"
+	agg(snaps)
+	if calls != 2 {
+		t.Errorf("after modifying the snapshots, the aggregation ran %d times, want 2", calls)
+	}
+}
+
+func TestAggCacheCopies(t *testing.T) {
+	c := NewAggCache()
+	totals := map[string]time.Duration{"GoLand": time.Minute}
+	c.Put("key", totals)
+	totals["GoLand"] = time.Hour
+	got, _ := c.Get("key")
+	got["Slack"] = time.Second
+	if got, ok := c.Get("key"); !ok || !reflect.DeepEqual(got, map[string]time.Duration{"GoLand": time.Minute}) {
+		t.Errorf("Get = %v, %t after modifying the totals put and got", got, ok)
+	}
+}
+
+// largeRecording returns days of recordings like testRecording, one
+// after the other.
+func largeRecording(days int) []*Snapshot {
+	var snaps []*Snapshot
+	for d := 0; d < days; d++ {
+		for _, snap := range testRecording() {
+			snap.Time = snap.Time.AddDate(0, 0, d)
+			snaps = append(snaps, snap)
+		}
+	}
+	return snaps
+}
+
+func TestWithCacheLarge(t *testing.T) {
+	calls := 0
+	agg := WithCache(NewAggCache(), "AggregateByApp", func(snaps []*Snapshot) map[string]time.Duration {
+		calls++
+		return AggregateByApp(snaps)
+	})
+	snaps := largeRecording(30)
+	want := agg(snaps)
+	for i := 0; i < 3; i++ {
+		if got := agg(snaps); !reflect.DeepEqual(got, want) {
+			t.Errorf("cached call returned %v, want %v", got, want)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("the aggregation ran %d times, want 1", calls)
+	}
+}
+
+func BenchmarkWithCacheHit(b *testing.B) {
+	agg := WithCache(NewAggCache(), "AggregateByApp", AggregateByApp)
+	snaps := largeRecording(30)
+	agg(snaps)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		agg(snaps)
+	}
+}
+
+func BenchmarkWithCacheMiss(b *testing.B) {
+	snaps := largeRecording(30)
+	for i := 0; i < b.N; i++ {
+		AggregateByApp(snaps)
+	}
+}
@@ -0,0 +1,80 @@
+package thyme
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// icalTimeFormat is the layout of UTC date-times in iCalendar.
+const icalTimeFormat = "20060102T150405Z"
+
+// icalEscaper escapes iCalendar text values.
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// blockUID returns a unique identifier of the calendar event of b, which
+// is stable, so that pushing the same block again replaces its event.
+func blockUID(b Block) string {
+	h := fnv.New32a()
+	io.WriteString(h, b.App)
+	return fmt.Sprintf("thyme-%d-%x", b.Start.Unix(), h.Sum32())
+}
+
+// blockEvent returns an iCalendar object with a single VEVENT covering b.
+func blockEvent(b Block, uid string) string {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//thyme//thyme//EN",
+		"BEGIN:VEVENT",
+		"UID:" + uid,
+		"DTSTAMP:" + time.Now().UTC().Format(icalTimeFormat),
+		"DTSTART:" + b.Start.UTC().Format(icalTimeFormat),
+		"DTEND:" + b.End.UTC().Format(icalTimeFormat),
+		"SUMMARY:" + icalEscaper.Replace(b.App),
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// PushCalDAV creates a calendar event for each focus block (see
+// FocusBlocks) of snaps lasting at least minBlock in the CalDAV calendar
+// collection at url, authenticating as user with pass. Events are
+// identified by their block, so pushing the same recording again updates
+// rather than duplicates its events. It stops at the first failed
+// request.
+func PushCalDAV(ctx context.Context, url, user, pass string, snaps []*Snapshot, minBlock time.Duration) error {
+	url = strings.TrimSuffix(url, "/")
+	for _, b := range FocusBlocks(snaps) {
+		if b.Duration() < minBlock {
+			continue
+		}
+		uid := blockUID(b)
+		req, err := http.NewRequestWithContext(ctx, "PUT", url+"/"+uid+".ics", strings.NewReader(blockEvent(b, uid)))
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(user, pass)
+		req.Header.Set("Content-Type", "text/calendar; charset=utf-8")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+			return fmt.Errorf("CalDAV server rejected the credentials of user %q: %s", user, resp.Status)
+		case resp.StatusCode < 200 || resp.StatusCode > 299:
+			return fmt.Errorf("CalDAV PUT of event %s failed: %s", uid, resp.Status)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package thyme
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testCalDAVRecording returns a recording with focus blocks of 30, 2,
+// and 20 minutes.
+func testCalDAVRecording() []*Snapshot {
+	return spans(span{"main.go - GoLand", 30}, span{"general - Acme - Slack", 2}, span{"Inbox - Gmail - Google Chrome", 20})
+}
+
+func TestPushCalDAV(t *testing.T) {
+	var mu sync.Mutex
+	puts := 0
+	bodies := make(map[string]string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me" || pass != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != "PUT" || !strings.HasPrefix(r.Header.Get("Content-Type"), "text/calendar") {
+			t.Errorf("got %s request with content type %q, want a PUT of text/calendar", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		puts++
+		bodies[r.URL.Path] = string(body)
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	if err := PushCalDAV(context.Background(), srv.URL+"/cal/", "me", "secret", testCalDAVRecording(), 10*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if puts != 2 || len(bodies) != 2 {
+		t.Fatalf("got %d PUTs of %d distinct events, want 2 of 2", puts, len(bodies))
+	}
+	for path, body := range bodies {
+		if !strings.HasPrefix(path, "/cal/thyme-") || !strings.HasSuffix(path, ".ics") {
+			t.Errorf("event PUT to %q, want /cal/thyme-*.ics", path)
+		}
+		lines := strings.Split(strings.TrimSuffix(body, "\r\n"), "\r\n")
+		if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-1] != "END:VCALENDAR" || strings.Count(body, "BEGIN:VEVENT") != 1 || strings.Count(body, "END:VEVENT") != 1 {
+			t.Errorf("event %s isn't a calendar with a single event:\n%s", path, body)
+		}
+		uid := "UID:" + strings.TrimSuffix(strings.TrimPrefix(path, "/cal/"), ".ics")
+		for _, prop := range []string{"VERSION:2.0", uid, "DTSTART:", "DTEND:", "SUMMARY:"} {
+			if !strings.Contains(body, "\r\n"+prop) {
+				t.Errorf("event %s lacks %s:\n%s", path, prop, body)
+			}
+		}
+	}
+
+	if err := PushCalDAV(context.Background(), srv.URL+"/cal", "me", "wrong", testCalDAVRecording(), 10*time.Minute); err == nil || !strings.Contains(err.Error(), "credentials") {
+		t.Errorf("with wrong credentials, PushCalDAV returned %v, want an error about the credentials", err)
+	}
+}
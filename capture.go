@@ -0,0 +1,178 @@
+package thyme
+
+import "log"
+
+// CaptureOptions controls post-processing applied to every snapshot a
+// tracker returns.
+type CaptureOptions struct {
+	// MaxWindows caps the number of windows recorded in each snapshot
+	// (see Snapshot.LimitWindows). Zero means no limit.
+	MaxWindows int
+
+	// IdleDetector, if set, is used to populate Snapshot.Idle. If it
+	// fails, a warning is logged and the snapshot is recorded without
+	// idle time.
+	IdleDetector IdleDetector
+
+	// CollapseByClass collapses the windows of each class in each
+	// snapshot (see Snapshot.CollapseByClass).
+	CollapseByClass bool
+}
+
+// captureTracker is a Tracker that applies CaptureOptions to the
+// snapshots of an underlying Tracker.
+type captureTracker struct {
+	Tracker
+	opts CaptureOptions
+}
+
+// WithCaptureOptions returns a Tracker that applies opts to every
+// snapshot returned by t.
+func WithCaptureOptions(t Tracker, opts CaptureOptions) Tracker {
+	return &captureTracker{Tracker: t, opts: opts}
+}
+
+func (t *captureTracker) Snap() (*Snapshot, error) {
+	snap, err := t.Tracker.Snap()
+	if err != nil {
+		return nil, err
+	}
+	if t.opts.IdleDetector != nil {
+		idle, err := t.opts.IdleDetector.IdleTime()
+		if err != nil {
+			log.Printf("warning: could not detect idle time: %s", err)
+		} else {
+			snap.Idle = idle
+		}
+	}
+	if t.opts.CollapseByClass {
+		snap = snap.CollapseByClass()
+	}
+	if t.opts.MaxWindows > 0 {
+		snap = snap.LimitWindows(t.opts.MaxWindows)
+	}
+	return snap, nil
+}
+
+// teeTracker is a Tracker that passes every snapshot of an underlying
+// Tracker to sinks.
+type teeTracker struct {
+	Tracker
+	sinks []func(*Snapshot)
+}
+
+// Tee returns a Tracker whose Snap calls t.Snap once and passes the
+// resulting snapshot to each of sinks, in order, before returning it
+// (e.g., to display snapshots live while they are also recorded). A
+// sink that panics is logged and doesn't affect the others.
+func Tee(t Tracker, sinks ...func(*Snapshot)) Tracker {
+	return &teeTracker{Tracker: t, sinks: sinks}
+}
+
+func (t *teeTracker) Snap() (*Snapshot, error) {
+	snap, err := t.Tracker.Snap()
+	if err != nil {
+		return nil, err
+	}
+	for _, sink := range t.sinks {
+		runSink(sink, snap)
+	}
+	return snap, nil
+}
+
+// runSink passes snap to sink, logging rather than propagating a panic.
+func runSink(sink func(*Snapshot), snap *Snapshot) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("warning: snapshot sink failed: %v", r)
+		}
+	}()
+	sink(snap)
+}
+
+// LimitWindows returns a copy of the snapshot with at most max windows.
+// The active and visible windows are always kept, even if they alone
+// exceed max; the remaining slots are filled with the other windows in
+// the order they were captured, and the rest are dropped.
+func (s *Snapshot) LimitWindows(max int) *Snapshot {
+	if len(s.Windows) <= max {
+		c := *s
+		c.Windows = append([]*Window(nil), s.Windows...)
+		return &c
+	}
+	visible := make(map[int64]struct{}, len(s.Visible))
+	for _, v := range s.Visible {
+		visible[v] = struct{}{}
+	}
+
+	keep := make(map[*Window]struct{}, max)
+	for _, w := range s.Windows {
+		if _, isVisible := visible[w.ID]; isVisible || w.ID == s.Active {
+			keep[w] = struct{}{}
+		}
+	}
+	for _, w := range s.Windows {
+		if len(keep) >= max {
+			break
+		}
+		keep[w] = struct{}{}
+	}
+
+	c := *s
+	c.Windows = make([]*Window, 0, len(keep))
+	for _, w := range s.Windows {
+		if _, kept := keep[w]; kept {
+			c.Windows = append(c.Windows, w)
+		}
+	}
+	return &c
+}
+
+// CollapseByClass returns a copy of the snapshot in which the windows of
+// each class that are neither active nor visible are collapsed into a
+// single representative: the first active or visible window of the
+// class, or else the first window of the class. The Collapsed count of
+// the representative is increased by the number of windows it replaced.
+// Windows without a class are kept.
+func (s *Snapshot) CollapseByClass() *Snapshot {
+	shown := make(map[int64]struct{}, len(s.Visible)+1)
+	shown[s.Active] = struct{}{}
+	for _, v := range s.Visible {
+		shown[v] = struct{}{}
+	}
+
+	// Choose the representative of each class.
+	reps := make(map[string]*Window)
+	for _, w := range s.Windows {
+		if _, isShown := shown[w.ID]; isShown && w.Class != "" && reps[w.Class] == nil {
+			reps[w.Class] = w
+		}
+	}
+	for _, w := range s.Windows {
+		if w.Class != "" && reps[w.Class] == nil {
+			reps[w.Class] = w
+		}
+	}
+
+	dropped := make(map[string]int)
+	for _, w := range s.Windows {
+		if _, isShown := shown[w.ID]; w.Class != "" && !isShown && reps[w.Class] != w {
+			dropped[w.Class]++
+		}
+	}
+
+	c := *s
+	c.Windows = make([]*Window, 0, len(s.Windows))
+	for _, w := range s.Windows {
+		if _, isShown := shown[w.ID]; w.Class != "" && !isShown && reps[w.Class] != w {
+			continue
+		}
+		if reps[w.Class] == w && dropped[w.Class] > 0 {
+			wc := *w
+			wc.Collapsed += dropped[w.Class]
+			w = &wc
+		}
+		c.Windows = append(c.Windows, w)
+	}
+	return &c
+}
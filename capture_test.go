@@ -0,0 +1,141 @@
+package thyme
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// stubTracker is a Tracker that returns a fixed snapshot and counts the
+// calls to Snap.
+type stubTracker struct {
+	snap  *Snapshot
+	err   error
+	calls int
+}
+
+func (t *stubTracker) Snap() (*Snapshot, error) {
+	t.calls++
+	return t.snap, t.err
+}
+
+func (t *stubTracker) Deps() string { return "" }
+
+func TestLimitWindows(t *testing.T) {
+	snap := &Snapshot{Active: 50, Visible: []int64{20, 30}}
+	for id := int64(1); id <= 50; id++ {
+		snap.Windows = append(snap.Windows, &Window{ID: id, Name: fmt.Sprintf("window %d", id)})
+	}
+	tracker := WithCaptureOptions(&stubTracker{snap: snap}, CaptureOptions{MaxWindows: 10})
+	limited, err := tracker.Snap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limited == snap {
+		t.Errorf("Snap returned the snapshot itself, not a copy")
+	}
+	if len(snap.Windows) != 50 {
+		t.Errorf("the original snapshot has %d windows, want 50", len(snap.Windows))
+	}
+	// The active and visible windows are kept, and the other windows are
+	// capped to fill the remaining slots in the order they were captured.
+	var got []int64
+	for _, w := range limited.Windows {
+		got = append(got, w.ID)
+	}
+	if want := []int64{1, 2, 3, 4, 5, 6, 7, 20, 30, 50}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MaxWindows 10 kept windows %v, want %v", got, want)
+	}
+	if limited.Active != 50 || !reflect.DeepEqual(limited.Visible, snap.Visible) {
+		t.Errorf("limited snapshot has active window %d and visible windows %v, want 50 and %v", limited.Active, limited.Visible, snap.Visible)
+	}
+
+	// The active and visible windows are kept even if they alone exceed
+	// the limit.
+	if got := snap.LimitWindows(1); len(got.Windows) != 3 {
+		t.Errorf("LimitWindows(1) kept %d windows, want the 3 active and visible ones", len(got.Windows))
+	}
+}
+
+func TestCollapseByClass(t *testing.T) {
+	snap := &Snapshot{
+		Windows: []*Window{
+			{ID: 1, Name: "a", Class: "Code"},
+			{ID: 2, Name: "b", Class: "Code"},
+			{ID: 3, Name: "c", Class: "Code"},
+			{ID: 4, Name: "d", Class: "Slack"},
+			{ID: 5, Name: "e"},
+		},
+		Active: 2,
+	}
+	c := snap.CollapseByClass()
+	want := map[int64]int{2: 2, 4: 0, 5: 0}
+	if len(c.Windows) != len(want) {
+		t.Fatalf("CollapseByClass kept %d windows, want %d", len(c.Windows), len(want))
+	}
+	for _, w := range c.Windows {
+		if collapsed, ok := want[w.ID]; !ok || w.Collapsed != collapsed {
+			t.Errorf("window %d kept with Collapsed %d, want %v", w.ID, w.Collapsed, want)
+		}
+	}
+	if snap.Windows[1].Collapsed != 0 {
+		t.Errorf("CollapseByClass modified the original window")
+	}
+}
+
+// stubIdleDetector is an IdleDetector that reports a fixed idle time.
+type stubIdleDetector struct {
+	idle time.Duration
+	err  error
+}
+
+func (d stubIdleDetector) IdleTime() (time.Duration, error) { return d.idle, d.err }
+
+func TestIdleDetector(t *testing.T) {
+	tests := []struct {
+		detector IdleDetector
+		want     time.Duration
+	}{
+		{stubIdleDetector{idle: 10 * time.Minute}, 10 * time.Minute},
+		// A failing detector leaves the idle time unknown.
+		{stubIdleDetector{err: errors.New("no display")}, 0},
+	}
+	for _, test := range tests {
+		snap := session(time.Minute, "main.go - GoLand")[0]
+		tracker := WithCaptureOptions(&stubTracker{snap: snap}, CaptureOptions{IdleDetector: test.detector})
+		got, err := tracker.Snap()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Idle != test.want || got.IsIdle() != (test.want >= IdleThreshold) {
+			t.Errorf("with detector %+v, Idle = %s (idle: %t), want %s", test.detector, got.Idle, got.IsIdle(), test.want)
+		}
+	}
+}
+
+func TestTee(t *testing.T) {
+	stub := &stubTracker{snap: session(time.Minute, "main.go - GoLand")[0]}
+	var a, b []*Snapshot
+	tracker := Tee(stub,
+		func(snap *Snapshot) { a = append(a, snap) },
+		// A failing sink doesn't affect the others.
+		func(*Snapshot) { panic("sink failed") },
+		func(snap *Snapshot) { b = append(b, snap) },
+	)
+	for i := 1; i <= 2; i++ {
+		snap, err := tracker.Snap()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stub.calls != i || len(a) != i || len(b) != i || a[i-1] != snap || b[i-1] != snap {
+			t.Fatalf("after %d calls to Snap, the tracker was called %d times and the sinks received %d and %d snapshots, want %d each of the snapshot returned", i, stub.calls, len(a), len(b), i)
+		}
+	}
+
+	stub.err = errors.New("no display")
+	if _, err := tracker.Snap(); err == nil || len(a) != 2 || len(b) != 2 {
+		t.Errorf("when the tracker fails, Snap returned %v and the sinks received %d and %d snapshots, want an error and 2 each", err, len(a), len(b))
+	}
+}
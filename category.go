@@ -0,0 +1,141 @@
+package thyme
+
+const (
+	// BrowserCategory is the category of web browsers.
+	BrowserCategory = "Browser"
+
+	// CommunicationCategory is the category of chat, email, and
+	// video-call applications.
+	CommunicationCategory = "Communication"
+
+	// DevelopmentCategory is the category of editors and IDEs.
+	DevelopmentCategory = "Development"
+
+	// OtherCategory is the category of applications that haven't been
+	// assigned one.
+	OtherCategory = "Other"
+)
+
+// categories maps application names (as they appear in Winfo.App) to
+// their category.
+var categories = map[string]string{
+	"Google Chrome":  BrowserCategory,
+	ChromeIncognito:  BrowserCategory,
+	"Chromium":       BrowserCategory,
+	"Firefox":        BrowserCategory,
+	"Microsoft Edge": BrowserCategory,
+	"Safari":         BrowserCategory,
+	"Brave":          BrowserCategory,
+	"Opera":          BrowserCategory,
+	"Vivaldi":        BrowserCategory,
+
+	"Slack":           CommunicationCategory,
+	"Microsoft Teams": CommunicationCategory,
+	"Discord":         CommunicationCategory,
+	"Zoom":            CommunicationCategory,
+	"zoom.us":         CommunicationCategory,
+	"Webex":           CommunicationCategory,
+	"Skype":           CommunicationCategory,
+	"Thunderbird":     CommunicationCategory,
+	"Mail":            CommunicationCategory,
+	"Outlook":         CommunicationCategory,
+	"Signal":          CommunicationCategory,
+	"Telegram":        CommunicationCategory,
+
+	"Visual Studio Code":            DevelopmentCategory,
+	"Visual Studio Code - Insiders": DevelopmentCategory,
+	"Code - OSS":                    DevelopmentCategory,
+	"VSCodium":                      DevelopmentCategory,
+	"Cursor":                        DevelopmentCategory,
+	"Positron":                      DevelopmentCategory,
+	"GoLand":                        DevelopmentCategory,
+	"IntelliJ IDEA":                 DevelopmentCategory,
+	"Sublime Text":                  DevelopmentCategory,
+	"Emacs":                         DevelopmentCategory,
+	"Vim":                           DevelopmentCategory,
+	"Neovim":                        DevelopmentCategory,
+	"Xcode":                         DevelopmentCategory,
+}
+
+// browserClasses maps the window classes of web browsers to their
+// application name, to recognize browser windows whose names can't be
+// parsed.
+var browserClasses = map[string]string{
+	"firefox":        "Firefox",
+	"Firefox":        "Firefox",
+	"Navigator":      "Firefox",
+	"Google-chrome":  "Google Chrome",
+	"google-chrome":  "Google Chrome",
+	"Chromium":       "Chromium",
+	"chromium":       "Chromium",
+	"Microsoft-edge": "Microsoft Edge",
+	"Brave-browser":  "Brave",
+	"Vivaldi-stable": "Vivaldi",
+	"Opera":          "Opera",
+}
+
+// appAliases maps alternative names of applications to their canonical
+// name.
+var appAliases = map[string]string{
+	"Mozilla Firefox": "Firefox",
+}
+
+// RegisterAppAlias makes alias an alternative name of the application
+// app, so that windows of either are grouped together under app.
+func RegisterAppAlias(alias, app string) {
+	appAliases[alias] = app
+}
+
+// canonicalApp returns the canonical name of the application app.
+func canonicalApp(app string) string {
+	if canonical, ok := appAliases[app]; ok {
+		return canonical
+	}
+	return app
+}
+
+// displayNames maps the keys under which statistics are grouped (e.g.,
+// window classes) to the names shown for them in reports.
+var displayNames = map[string]string{}
+
+// RegisterDisplayName makes reports show the key under which statistics
+// are grouped (e.g., the window class "google-chrome-stable") as pretty
+// (e.g., "Chrome"). The key itself is still used for grouping.
+func RegisterDisplayName(key, pretty string) {
+	displayNames[key] = pretty
+}
+
+// DisplayName returns the name reports show for key: its registered
+// display name, if any, or else key itself.
+func DisplayName(key string) string {
+	if pretty, ok := displayNames[key]; ok {
+		return pretty
+	}
+	return key
+}
+
+// RegisterCategory assigns category to the application app, replacing
+// any category previously assigned to it.
+func RegisterCategory(app, category string) {
+	categories[app] = category
+}
+
+// Category returns the category of the window's application, or
+// OtherCategory if the application has no registered category.
+func (w Winfo) Category() string {
+	return appCategory(w.App)
+}
+
+// appCategory returns the category of the application app, or
+// OtherCategory if the application has no registered category.
+func appCategory(app string) string {
+	if c, ok := categories[canonicalApp(app)]; ok {
+		return c
+	}
+	return OtherCategory
+}
+
+// IsBrowser returns true if the window belongs to a web browser.
+func (w Winfo) IsBrowser() bool {
+	return w.Category() == BrowserCategory
+}
@@ -0,0 +1,42 @@
+package thyme
+
+import "fmt"
+
+// Classify returns the metadata Window.Info extracts from a window
+// named name, which is useful to try out how a window name is parsed.
+func Classify(name string) *Winfo {
+	return (&Window{Name: name}).Info()
+}
+
+// ClassifyExplain is like Classify, but also returns a human-readable
+// explanation of which title parser recognized the name and whether an
+// application alias applies to the result.
+func ClassifyExplain(name string) (*Winfo, string) {
+	info, parser := (&Window{Name: name}).parse()
+	var reason string
+	switch description := parserDescription(parser); {
+	case parser == "":
+		reason = "no separator recognized: the whole name is the title"
+	case description != "":
+		reason = fmt.Sprintf("%s parser: %s", parser, description)
+	default:
+		reason = fmt.Sprintf("%s parser (registered with RegisterTitleParser)", parser)
+	}
+	if app := canonicalApp(info.App); app != info.App {
+		reason += fmt.Sprintf("; %q is an alias of %q", info.App, app)
+	}
+	return info, reason
+}
+
+// parserDescription returns the description of the title parser named
+// name, or "" if it has none.
+func parserDescription(name string) string {
+	for _, parsers := range [][]TitleParser{specialTitleParsers, genericTitleParsers} {
+		for _, p := range parsers {
+			if p.Name == name {
+				return p.Description
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package thyme
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassifyExplain(t *testing.T) {
+	tests := []struct {
+		name, wantApp string
+		wantReason    []string
+	}{
+		{"Inbox - Gmail - Google Chrome", "Google Chrome", []string{"chrome parser", "Google Chrome special case"}},
+		{"Page — Mozilla Firefox", "Mozilla Firefox", []string{"firefox parser", `"Mozilla Firefox" is an alias of "Firefox"`}},
+		{"main.go - GoLand", "GoLand", []string{"app-last parser"}},
+		{"Terminal", "", []string{"no separator recognized"}},
+	}
+	for _, test := range tests {
+		info, reason := ClassifyExplain(test.name)
+		if info.App != test.wantApp {
+			t.Errorf("ClassifyExplain(%q) has App %q, want %q", test.name, info.App, test.wantApp)
+		}
+		for _, want := range test.wantReason {
+			if !strings.Contains(reason, want) {
+				t.Errorf("ClassifyExplain(%q) explained %q, want it to mention %q", test.name, reason, want)
+			}
+		}
+	}
+}
+
+func TestClassifyExplainRegistered(t *testing.T) {
+	saved := specialTitleParsers
+	defer func() { specialTitleParsers = saved }()
+	parse := func(w *Window) *Winfo {
+		if strings.HasPrefix(w.Name, "ticket ") {
+			return &Winfo{App: "Tracker", Title: w.Name}
+		}
+		return nil
+	}
+	RegisterTitleParser(TitleParser{Name: "tracker", Parse: parse, Description: "issue tracker ticket"})
+	RegisterTitleParser(TitleParser{Name: "bare", Parse: func(w *Window) *Winfo {
+		if w.Name == "bare" {
+			return &Winfo{App: "Bare"}
+		}
+		return nil
+	}})
+	if _, reason := ClassifyExplain("ticket 42"); reason != "tracker parser: issue tracker ticket" {
+		t.Errorf("explanation of a registered parser = %q", reason)
+	}
+	if _, reason := ClassifyExplain("bare"); !strings.Contains(reason, "RegisterTitleParser") {
+		t.Errorf("explanation of a registered parser without a description = %q", reason)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/exec"
 	"runtime"
 	"strconv"
 	"time"
@@ -32,6 +33,7 @@ func init() {
 			  thyme track -o <file>
 			  thyme show  -i <file> -w stats > viz.html
 			  thyme trackbg -o <file>
+			  thyme classify "<window name>"
 
 			`
 
@@ -47,11 +49,16 @@ func init() {
 	if _, err := CLI.AddCommand("trackbg", "record windows in background", "same with track command, but this will leaves thyme as process in background", &trackBg); err != nil {
 		log.Fatal(err)
 	}
+	if _, err := CLI.AddCommand("classify", "show how a window name is parsed", "Show the metadata Thyme extracts from each window name given as an argument, and which rule produced it. Useful for tuning the title parsing heuristics.", &classifyCmd); err != nil {
+		log.Fatal(err)
+	}
 }
 
 // TrackBackground is the subcommand that tracks application usage in background
 type TrackBackground struct {
-	Out string `long:"out" short:"o" description:"output file"`
+	Out             string `long:"out" short:"o" description:"output file"`
+	MaxWindows      int    `long:"max-windows" description:"maximum number of windows recorded per snapshot (active and visible windows are always recorded)"`
+	CollapseByClass bool   `long:"collapse-by-class" description:"record only one hidden window per window class, along with the number of windows it stands for"`
 }
 
 var trackBg TrackBackground
@@ -62,6 +69,8 @@ func (bg *TrackBackground) Execute([]string) error {
 		return err
 	}
 
+	t = thyme.WithCaptureOptions(t, thyme.CaptureOptions{MaxWindows: bg.MaxWindows, IdleDetector: getIdleDetector(), CollapseByClass: bg.CollapseByClass})
+
 	if bg.Out == "" {
 		filename := "snapshot-" + now()
 		bg.Out = filename
@@ -92,7 +101,9 @@ func (bg *TrackBackground) loop(t thyme.Tracker) {
 
 // TrackCmd is the subcommand that tracks application usage.
 type TrackCmd struct {
-	Out string `long:"out" short:"o" description:"output file"`
+	Out             string `long:"out" short:"o" description:"output file"`
+	MaxWindows      int    `long:"max-windows" description:"maximum number of windows recorded per snapshot (active and visible windows are always recorded)"`
+	CollapseByClass bool   `long:"collapse-by-class" description:"record only one hidden window per window class, along with the number of windows it stands for"`
 }
 
 var trackCmd TrackCmd
@@ -102,6 +113,7 @@ func (c *TrackCmd) Execute(args []string) error {
 	if err != nil {
 		return err
 	}
+	t = thyme.WithCaptureOptions(t, thyme.CaptureOptions{MaxWindows: c.MaxWindows, IdleDetector: getIdleDetector(), CollapseByClass: c.CollapseByClass})
 	snap, err := t.Snap()
 	if err != nil {
 		return err
@@ -201,6 +213,19 @@ func (c *DepCmd) Execute(args []string) error {
 	return nil
 }
 
+// ClassifyCmd is the subcommand that shows how window names are parsed.
+type ClassifyCmd struct{}
+
+var classifyCmd ClassifyCmd
+
+func (c *ClassifyCmd) Execute(args []string) error {
+	for _, name := range args {
+		info, reason := thyme.ClassifyExplain(name)
+		fmt.Printf("%q\n\t%s\n\t%s\n", name, info.Print(), reason)
+	}
+	return nil
+}
+
 func main() {
 	run := func() error {
 		_, err := CLI.Parse()
@@ -232,6 +257,19 @@ func getTracker() (thyme.Tracker, error) {
 	}
 }
 
+func getIdleDetector() thyme.IdleDetector {
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		return nil
+	default:
+		if _, err := exec.LookPath("xprintidle"); err != nil {
+			log.Printf("warning: xprintidle not found, idle time will not be recorded")
+			return nil
+		}
+		return thyme.XprintidleDetector{}
+	}
+}
+
 func now() string {
 	return strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
 }
@@ -1,3 +1,6 @@
+//go:build !js
+// +build !js
+
 package thyme
 
 import (
@@ -98,7 +101,10 @@ func (t *DarwinTracker) Snap() (*Snapshot, error) {
 		}
 	}
 
-	var visible []int64
+	// An empty (rather than nil) Visible tells a snapshot in which no
+	// window was visible apart from one whose visible windows are
+	// unknown.
+	visible := []int64{}
 	{
 		procWins, err := runAS(visibleWindowsScript)
 		if err != nil {
@@ -3,7 +3,7 @@ package thyme
 import (
 	"bytes"
 	"fmt"
-	"strings"
+	"sort"
 	"time"
 )
 
@@ -13,7 +13,23 @@ type Snapshot struct {
 	Time    time.Time
 	Windows []*Window
 	Active  int64
+
+	// Visible are the IDs of the visible windows. It is nil if the
+	// visible windows are unknown (e.g., in recordings made before they
+	// were recorded), as opposed to empty if no window was visible; see
+	// AssumeActiveVisible.
 	Visible []int64
+
+	// Desktop is the numerical identifier of the current desktop.
+	Desktop int64 `json:",omitempty"`
+
+	// Idle is how long the user had been idle when the snapshot was
+	// taken. It is zero if idle time wasn't detected.
+	Idle time.Duration `json:",omitempty"`
+
+	// Outputs are the display outputs (monitors) connected when the
+	// snapshot was taken, if known.
+	Outputs []Output `json:",omitempty"`
 }
 
 // Print returns a pretty-printed representation of the snapshot.
@@ -59,6 +75,149 @@ s_Windows:
 	return string(b.Bytes())
 }
 
+// AssumeActiveVisible makes snapshots whose visible windows are unknown
+// (i.e., whose Visible is nil) be treated as if the active window was the
+// only visible window. Otherwise, their visible windows are treated as
+// unknown: they have none, and are never considered fullscreen (see
+// Snapshot.IsFullscreenFocus).
+var AssumeActiveVisible = false
+
+// visibleIDs returns the IDs of the visible windows of the snapshot,
+// applying AssumeActiveVisible, or nil if they are unknown.
+func (s Snapshot) visibleIDs() []int64 {
+	if s.Visible == nil && AssumeActiveVisible && s.Active != 0 {
+		return []int64{s.Active}
+	}
+	return s.Visible
+}
+
+// ActiveWindow returns the active window of the snapshot, or nil if
+// there is no active window or the active window is a system window.
+func (s Snapshot) ActiveWindow() *Window {
+	if w := s.window(s.Active); w != nil && !w.IsSystem() {
+		return w
+	}
+	return nil
+}
+
+// VisibleWindows returns the visible windows of the snapshot, in the
+// order of Visible, excluding the active window (which some window
+// managers also list as visible). Each window is returned at most once.
+// It returns nil if the visible windows are unknown.
+func (s Snapshot) VisibleWindows() []*Window {
+	var visible []*Window
+	ids := s.visibleIDs()
+	seen := make(map[int64]bool, len(ids))
+	for _, v := range ids {
+		if v == s.Active || seen[v] {
+			continue
+		}
+		seen[v] = true
+		if w := s.window(v); w != nil {
+			visible = append(visible, w)
+		}
+	}
+	return visible
+}
+
+// WindowsByRelevance returns all windows of the snapshot, ordered by
+// relevance to the user: the active window first, then the visible
+// windows in the order of Visible, and then the other windows by
+// application and title.
+func (s Snapshot) WindowsByRelevance() []*Window {
+	var windows []*Window
+	listed := make(map[*Window]bool, len(s.Windows))
+	if w := s.window(s.Active); w != nil {
+		windows = append(windows, w)
+		listed[w] = true
+	}
+	for _, w := range s.VisibleWindows() {
+		windows = append(windows, w)
+		listed[w] = true
+	}
+	var other []*Window
+	for _, w := range s.Windows {
+		if !listed[w] {
+			other = append(other, w)
+		}
+	}
+	sort.SliceStable(other, func(i, j int) bool {
+		a, b := other[i].Info(), other[j].Info()
+		if appA, appB := canonicalApp(a.App), canonicalApp(b.App); appA != appB {
+			return appA < appB
+		}
+		return a.Title < b.Title
+	})
+	return append(windows, other...)
+}
+
+// UnknownApp is the key ActiveAppKey returns for snapshots whose active
+// application can't be determined, as when there is no active
+// application window. Statistics don't attribute time to it.
+const UnknownApp = "(unknown)"
+
+// ActiveAppKey returns the key identifying the application of the
+// active window, as used to group windows by application throughout
+// Thyme's statistics: the application name, with aliases resolved (see
+// RegisterAppAlias), or else the sub-application or title of the
+// window, or else UnknownApp.
+func (s Snapshot) ActiveAppKey() string {
+	if app := s.activeAppID(); app != "" {
+		return app
+	}
+	return UnknownApp
+}
+
+// activeAppID returns the key of the application of the active window
+// (see ActiveAppKey), or "" if it can't be determined.
+func (s Snapshot) activeAppID() string {
+	if w := s.ActiveWindow(); w != nil {
+		return appID(w)
+	}
+	return ""
+}
+
+// IsFullscreenFocus returns true if an application window was active
+// while at most one window was visible, as when a fullscreen application
+// is in use. It returns false if the visible windows are unknown.
+func (s Snapshot) IsFullscreenFocus() bool {
+	ids := s.visibleIDs()
+	return s.ActiveWindow() != nil && ids != nil && len(ids) <= 1
+}
+
+// IsEmptyDesktop returns true if no windows were open at all.
+func (s Snapshot) IsEmptyDesktop() bool {
+	return len(s.Windows) == 0
+}
+
+// window returns the window of the snapshot with the specified ID, or
+// nil if there is none.
+func (s Snapshot) window(id int64) *Window {
+	for _, w := range s.Windows {
+		if w.ID == id {
+			return w
+		}
+	}
+	return nil
+}
+
+// clone returns a deep copy of the snapshot, so that its windows can be
+// modified without affecting the original.
+func (s *Snapshot) clone() *Snapshot {
+	c := *s
+	c.Windows = make([]*Window, len(s.Windows))
+	for i, w := range s.Windows {
+		wc := *w
+		c.Windows[i] = &wc
+	}
+	if s.Visible != nil {
+		// Keep an empty Visible empty, as nil means unknown.
+		c.Visible = append([]int64{}, s.Visible...)
+	}
+	c.Outputs = append([]Output(nil), s.Outputs...)
+	return &c
+}
+
 // Window represents an application window.
 type Window struct {
 	// ID is the numerical identifier of the window.
@@ -71,8 +230,41 @@ type Window struct {
 	// Name is the display name of the window (typically what the
 	// windowing system shows in the top bar of the window).
 	Name string
+
+	// Class identifies the program that owns the window (e.g., the
+	// WM_CLASS on X11), if known.
+	Class string `json:",omitempty"`
+
+	// Opacity is the opacity of the window, from 0 (fully transparent)
+	// to 1 (opaque, the default the Linux tracker records for windows
+	// that don't set their opacity). It is zero if the opacity is
+	// unknown (e.g., in recordings made before it was recorded, or on
+	// other platforms), in which case the window should be assumed to be
+	// opaque, so use IsTranslucent rather than comparing it with 1.
+	Opacity float64 `json:",omitempty"`
+
+	// Geometry is the area the window covers in screen coordinates, if
+	// known.
+	Geometry *Rect `json:",omitempty"`
+
+	// Output is the name of the display output the window is on, if
+	// known.
+	Output string `json:",omitempty"`
+
+	// Type is the type of the window (e.g., "normal", "dialog",
+	// "utility", or "menu", as in _NET_WM_WINDOW_TYPE on X11), if known.
+	Type string `json:",omitempty"`
+
+	// Collapsed is the number of other windows of the same class that
+	// this window stands for, because they were dropped from the
+	// snapshot (see Snapshot.CollapseByClass).
+	Collapsed int `json:",omitempty"`
 }
 
+// NormalWindowType is the Type of ordinary top-level application
+// windows.
+const NormalWindowType = "normal"
+
 // systemNames is a set of blacklisted window names that are known to
 // be used by system windows that aren't visible to the user.
 var systemNames = map[string]struct{}{
@@ -84,6 +276,31 @@ var systemNames = map[string]struct{}{
 	"Desktop":                 {},
 }
 
+// agentClasses is a set of window classes of background agents (e.g.,
+// macOS menu bar apps) whose windows aren't real application windows.
+var agentClasses = map[string]struct{}{
+	"SystemUIServer":      {},
+	"Control Center":      {},
+	"Notification Center": {},
+}
+
+// RegisterAgentClass registers the window class of a background agent,
+// so that its windows are considered system windows.
+func RegisterAgentClass(class string) {
+	agentClasses[class] = struct{}{}
+}
+
+// SystemZeroSize makes IsSystem treat windows with a known, empty
+// geometry as system windows, as menu bar agents often surface as such.
+// It is off by default because some window managers report a zero size
+// for minimized windows.
+var SystemZeroSize = false
+
+// SystemNonNormalTypes makes IsSystem treat windows of a known Type
+// other than NormalWindowType (e.g., dialogs, menus, and tooltips) as
+// system windows, so that they don't count as applications.
+var SystemNonNormalTypes = false
+
 // IsSystem returns true if the window is a system window (like
 // "unity-panel" and thus shouldn't be considered an application
 // visible to the end-users)
@@ -91,9 +308,38 @@ func (w *Window) IsSystem() bool {
 	if _, is := systemNames[w.Name]; is {
 		return true
 	}
+	if _, is := agentClasses[w.Class]; is {
+		return true
+	}
+	if SystemNonNormalTypes && w.Type != "" && w.Type != NormalWindowType {
+		return true
+	}
+	if SystemZeroSize && w.Geometry != nil && (w.Geometry.Width == 0 || w.Geometry.Height == 0) {
+		return true
+	}
 	return false
 }
 
+// IsTranslucent returns true if the window is known to be partially
+// transparent, as overlay and scratchpad windows often are.
+func (w *Window) IsTranslucent() bool {
+	return w.Opacity > 0 && w.Opacity < 1
+}
+
+// GroupKey returns the most reliable identifier available for grouping
+// the window with others of the same program: its class, if known, or
+// else its application, or else its title.
+func (w *Window) GroupKey() string {
+	if w.Class != "" {
+		return w.Class
+	}
+	info := w.Info()
+	if info.App != "" {
+		return canonicalApp(info.App)
+	}
+	return info.Title
+}
+
 // IsSticky returns true if the window is a sticky window (i.e.
 // present on all desktops)
 func (w *Window) IsSticky() bool {
@@ -106,66 +352,18 @@ func (w *Window) IsOnDesktop(desktop int64) bool {
 	return w.IsSticky() || w.Desktop == desktop
 }
 
-const (
-	defaultWindowTitleSeparator       = " - "
-	microsoftEdgeWindowTitleSeparator = "\u200e- "
-)
-
 // Info returns more structured metadata about a window. The metadata
-// is extracted using heuristics.
+// is extracted using heuristics: each of the title parsers (see
+// TitleParsers) is tried in order, and the first to recognize the window
+// name determines the result.
 //
 // Assumptions:
 //     1) Most windows use " - " to separate their window names from their content
 //     2) Most windows use the " - " with the application name at the end.
 //     3) The few programs that reverse this convention only reverse it.
 func (w *Window) Info() *Winfo {
-	// Special Cases
-	fields := strings.Split(w.Name, defaultWindowTitleSeparator)
-	if len(fields) > 1 {
-		last := strings.TrimSpace(fields[len(fields)-1])
-		if last == "Google Chrome" {
-			return &Winfo{
-				App:    "Google Chrome",
-				SubApp: strings.TrimSpace(fields[len(fields)-2]),
-				Title:  strings.Join(fields[0:len(fields)-2], defaultWindowTitleSeparator),
-			}
-		}
-	}
-
-	if strings.Contains(w.Name, microsoftEdgeWindowTitleSeparator) {
-		// App Name Last
-		beforeSep := strings.LastIndex(w.Name, microsoftEdgeWindowTitleSeparator)
-		afterSep := beforeSep + len(microsoftEdgeWindowTitleSeparator)
-		return &Winfo{
-			App:   strings.TrimSpace(w.Name[afterSep:]),
-			Title: strings.TrimSpace(w.Name[:beforeSep]),
-		}
-	}
-
-	// Normal Cases
-	if beforeSep := strings.Index(w.Name, defaultWindowTitleSeparator); beforeSep > -1 {
-		// App Name First
-		if w.Name[:beforeSep] == "Slack" {
-			afterSep := beforeSep + len(defaultWindowTitleSeparator)
-			return &Winfo{
-				App:   strings.TrimSpace(w.Name[:beforeSep]),
-				Title: strings.TrimSpace(w.Name[afterSep:]),
-			}
-		}
-
-		// App Name Last
-		beforeSep := strings.LastIndex(w.Name, defaultWindowTitleSeparator)
-		afterSep := beforeSep + len(defaultWindowTitleSeparator)
-		return &Winfo{
-			App:   strings.TrimSpace(w.Name[afterSep:]),
-			Title: strings.TrimSpace(w.Name[:beforeSep]),
-		}
-	}
-
-	// No Application name separator
-	return &Winfo{
-		Title: w.Name,
-	}
+	info, _ := w.parse()
+	return info
 }
 
 // Winfo is structured metadata info about a window.
@@ -182,6 +380,14 @@ type Winfo struct {
 	// Title is the title of the window after the App and SubApp name
 	// have been stripped.
 	Title string
+
+	// Raw is the unshortened title of the window, set only when Title
+	// was shortened (e.g., to the last segment of a folder path).
+	Raw string `json:",omitempty"`
+
+	// Project is the project the window belongs to, as determined by
+	// the patterns registered with RegisterProjectPattern, if any.
+	Project string `json:",omitempty"`
 }
 
 // Print returns a pretty-printed representation of the snapshot.
@@ -0,0 +1,203 @@
+package thyme
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testStart is the time of the first snapshot of test recordings.
+var testStart = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+// session returns a recording with a snapshot every step from
+// testStart, one for each of names, in which the only window, named
+// accordingly, is active. An empty name makes a snapshot without
+// windows.
+func session(step time.Duration, names ...string) []*Snapshot {
+	snaps := make([]*Snapshot, len(names))
+	for i, name := range names {
+		snap := &Snapshot{Time: testStart.Add(time.Duration(i) * step)}
+		if name != "" {
+			snap.Windows = []*Window{{ID: 1, Name: name}}
+			snap.Active = 1
+		}
+		snaps[i] = snap
+	}
+	return snaps
+}
+
+// span is a window name that stays active for a number of minutes.
+type span struct {
+	name    string
+	minutes int
+}
+
+// spans returns a recording with a snapshot every minute (see session)
+// in which the windows of spans are active in turn, followed by a
+// snapshot without windows that ends the last span.
+func spans(ss ...span) []*Snapshot {
+	var names []string
+	for _, s := range ss {
+		for i := 0; i < s.minutes; i++ {
+			names = append(names, s.name)
+		}
+	}
+	return session(time.Minute, append(names, "")...)
+}
+
+func TestActiveApp(t *testing.T) {
+	tests := []struct {
+		snap *Snapshot
+		want string
+	}{
+		{session(time.Minute, "main.go - GoLand")[0], "GoLand"},
+		{session(time.Minute, "")[0], ""},
+		{&Snapshot{Windows: []*Window{{ID: 1, Name: "main.go - GoLand"}}, Active: 2}, ""},
+		{&Snapshot{Windows: []*Window{{ID: 1, Name: "main.go - GoLand"}}, Active: 1, Idle: IdleThreshold}, ""},
+	}
+	for _, test := range tests {
+		if got := test.snap.activeApp(); got != test.want {
+			t.Errorf("activeApp of %+v = %q, want %q", test.snap, got, test.want)
+		}
+	}
+}
+
+func TestUnknownVisible(t *testing.T) {
+	windows := []*Window{{ID: 1, Name: "main.go - GoLand"}, {ID: 2, Name: "Inbox - Gmail - Google Chrome"}}
+	tests := []struct {
+		name           string
+		visible        []int64
+		assume         bool
+		wantVisible    []int64
+		wantFullscreen bool
+	}{
+		{name: "unknown", visible: nil, wantVisible: nil, wantFullscreen: false},
+		{name: "unknown, assumed active", visible: nil, assume: true, wantVisible: []int64{1}, wantFullscreen: true},
+		{name: "none", visible: []int64{}, wantVisible: []int64{}, wantFullscreen: true},
+		{name: "none, assumed active", visible: []int64{}, assume: true, wantVisible: []int64{}, wantFullscreen: true},
+		{name: "two", visible: []int64{1, 2}, assume: true, wantVisible: []int64{1, 2}, wantFullscreen: false},
+	}
+	defer func() { AssumeActiveVisible = false }()
+	for _, test := range tests {
+		AssumeActiveVisible = test.assume
+		snap := &Snapshot{Windows: windows, Active: 1, Visible: test.visible}
+		if got := snap.visibleIDs(); (got == nil) != (test.wantVisible == nil) || len(got) != len(test.wantVisible) || !sameIDs(got, test.wantVisible) {
+			t.Errorf("%s: visibleIDs = %#v, want %#v", test.name, got, test.wantVisible)
+		}
+		if got := snap.IsFullscreenFocus(); got != test.wantFullscreen {
+			t.Errorf("%s: IsFullscreenFocus = %t, want %t", test.name, got, test.wantFullscreen)
+		}
+		if c := snap.clone(); (c.Visible == nil) != (snap.Visible == nil) {
+			t.Errorf("%s: clone has Visible %#v, want %#v", test.name, c.Visible, snap.Visible)
+		}
+	}
+}
+
+func TestActiveAppKey(t *testing.T) {
+	RegisterAppAlias("Chromium", "Google Chrome")
+	defer delete(appAliases, "Chromium")
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Inbox - Gmail - Google Chrome", "Google Chrome"},
+		{"Inbox - Gmail - Chromium", "Google Chrome"},
+		{"Page — Mozilla Firefox", "Firefox"},
+		{"", UnknownApp},
+	}
+	for _, test := range tests {
+		snap := session(time.Minute, test.name)[0]
+		if got := snap.ActiveAppKey(); got != test.want {
+			t.Errorf("ActiveAppKey with %q active = %q, want %q", test.name, got, test.want)
+		}
+	}
+	if got := (&Snapshot{}).activeApp(); got != "" {
+		t.Errorf("activeApp without an active window = %q, want none", got)
+	}
+}
+
+func TestGroupKey(t *testing.T) {
+	tests := []struct {
+		w    *Window
+		want string
+	}{
+		{&Window{Name: "Inbox - Gmail - Google Chrome", Class: "google-chrome"}, "google-chrome"},
+		{&Window{Name: "Page — Mozilla Firefox"}, "Firefox"},
+		{&Window{Name: "Untitled"}, "Untitled"},
+	}
+	for _, test := range tests {
+		if got := test.w.GroupKey(); got != test.want {
+			t.Errorf("GroupKey of %+v = %q, want %q", test.w, got, test.want)
+		}
+	}
+}
+
+func TestActiveInVisible(t *testing.T) {
+	snap := &Snapshot{
+		Windows: []*Window{{ID: 1, Name: "main.go - GoLand"}, {ID: 2, Name: "Inbox - Gmail - Google Chrome"}},
+		Active:  1,
+		Visible: []int64{1, 2, 2},
+	}
+	if got := snap.VisibleWindows(); len(got) != 1 || got[0].ID != 2 {
+		t.Errorf("VisibleWindows = %+v, want only window 2", got)
+	}
+	out := snap.Print()
+	if n := strings.Count(out, "GoLand"); n != 1 || !strings.Contains(out, "Active: ") {
+		t.Errorf("Print lists the active window %d times, want once as active:\n%s", n, out)
+	}
+}
+
+func TestSystemZeroSize(t *testing.T) {
+	RegisterAgentClass("Dropbox")
+	defer delete(agentClasses, "Dropbox")
+	tests := []struct {
+		w    *Window
+		want bool // with SystemZeroSize
+	}{
+		{&Window{Name: "Item-0", Geometry: &Rect{Width: 0, Height: 22}}, true},
+		{&Window{Name: "Item-0", Geometry: &Rect{Width: 24, Height: 0}}, true},
+		{&Window{Name: "main.go - GoLand", Geometry: &Rect{Width: 1600, Height: 900}}, false},
+		// Windows of unknown geometry aren't zero-size.
+		{&Window{Name: "main.go - GoLand"}, false},
+	}
+	for _, test := range tests {
+		if test.w.IsSystem() {
+			t.Errorf("without SystemZeroSize, %+v is a system window", test.w)
+		}
+	}
+	SystemZeroSize = true
+	defer func() { SystemZeroSize = false }()
+	for _, test := range tests {
+		if got := test.w.IsSystem(); got != test.want {
+			t.Errorf("with SystemZeroSize, IsSystem of %+v = %t, want %t", test.w, got, test.want)
+		}
+	}
+	if w := (&Window{Name: "Dropbox", Class: "Dropbox"}); !w.IsSystem() {
+		t.Errorf("window of a registered agent class isn't a system window")
+	}
+}
+
+func TestWindowsByRelevance(t *testing.T) {
+	snap := &Snapshot{
+		Windows: []*Window{
+			{ID: 1, Name: "notes.txt - gedit"},
+			{ID: 2, Name: "main.go - GoLand"},
+			{ID: 3, Name: "data.go - GoLand"},
+			{ID: 4, Name: "Inbox - Gmail - Google Chrome"},
+			{ID: 5, Name: "general - Acme - Slack"},
+			{ID: 6, Name: "~/code - Terminal"},
+		},
+		Active: 4,
+		// Visible windows keep their order.
+		Visible: []int64{6, 4, 2},
+	}
+	var got []int64
+	for _, w := range snap.WindowsByRelevance() {
+		got = append(got, w.ID)
+	}
+	// The other windows are ordered by application, then title.
+	if want := []int64{4, 6, 2, 3, 5, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WindowsByRelevance = %v, want %v", got, want)
+	}
+}
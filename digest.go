@@ -0,0 +1,99 @@
+package thyme
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// Digest returns a short human-readable summary of snaps: the total
+// active time, the top 3 applications, the number of application
+// switches, and the longest focus block.
+func Digest(snaps []*Snapshot) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Active for %s.", roundDuration(TotalActive(snaps)))
+
+	top := sortByTime(AggregateByApp(snaps))
+	if len(top) > 3 {
+		top = top[:3]
+	}
+	if len(top) > 0 {
+		fmt.Fprintf(&b, " Top apps:")
+		for i, a := range top {
+			if i > 0 {
+				fmt.Fprintf(&b, ",")
+			}
+			fmt.Fprintf(&b, " %s (%s)", DisplayName(a.App), roundDuration(a.Time))
+		}
+		fmt.Fprintf(&b, ".")
+	}
+
+	fmt.Fprintf(&b, " %d switches.", CountSwitches(snaps))
+
+	if longest := longestBlock(FocusBlocks(snaps)); longest.App != "" {
+		fmt.Fprintf(&b, " Longest focus block: %s in %s, starting %s.", roundDuration(longest.Duration()), DisplayName(longest.App), longest.Start.Format("15:04"))
+	}
+	return b.String()
+}
+
+// roundDuration rounds d to the second for display.
+func roundDuration(d time.Duration) time.Duration {
+	return d.Round(time.Second)
+}
+
+// DeepWorkBlock is the minimum length of the focus blocks counted as
+// deep work by DayScorecard.
+const DeepWorkBlock = 25 * time.Minute
+
+// Scorecard summarizes how focused or fragmented the time of a recording
+// (typically a day) was.
+type Scorecard struct {
+	// TotalActive is the total time any application was active (see
+	// TotalActive).
+	TotalActive time.Duration
+
+	// DeepWork is the total time of focus blocks lasting at least
+	// DeepWorkBlock (see DeepWorkByDay).
+	DeepWork time.Duration
+
+	// Switches is the number of application switches (see
+	// CountSwitches).
+	Switches int
+
+	// LongestStreak is the longest focus block (see FocusBlocks).
+	LongestStreak Block
+
+	// SwitchRate is the number of switches per hour of active time.
+	SwitchRate float64
+
+	// Entropy is the entropy of the distribution of active time across
+	// applications, in bits (see AppEntropy).
+	Entropy float64
+
+	// TopApp is the application that was active the longest.
+	TopApp string
+}
+
+// DayScorecard returns the Scorecard of snaps, which combines the main
+// focus and fragmentation metrics into one summary.
+func DayScorecard(snaps []*Snapshot) Scorecard {
+	totals := AggregateByApp(snaps)
+	sc := Scorecard{
+		Switches:      CountSwitches(snaps),
+		LongestStreak: longestBlock(FocusBlocks(snaps)),
+		Entropy:       AppEntropy(snaps),
+	}
+	for _, t := range totals {
+		sc.TotalActive += t
+	}
+	for _, t := range DeepWorkByDay(snaps, time.Local, DeepWorkBlock) {
+		sc.DeepWork += t
+	}
+	if sc.TotalActive > 0 {
+		sc.SwitchRate = float64(sc.Switches) / sc.TotalActive.Hours()
+	}
+	if top := sortByTime(totals); len(top) > 0 {
+		sc.TopApp = top[0].App
+	}
+	return sc
+}
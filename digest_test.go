@@ -0,0 +1,49 @@
+package thyme
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDigest(t *testing.T) {
+	snaps := spans(span{"main.go - GoLand", 30}, span{"general - Acme - Slack", 5}, span{"main.go - GoLand", 10}, span{"Inbox - Gmail - Google Chrome", 5})
+	got := Digest(snaps)
+	for _, want := range []string{"Top apps: GoLand (40m0s)", "3 switches.", "Longest focus block: 30m0s in GoLand"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Digest = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestDayScorecard(t *testing.T) {
+	snaps := spans(span{"main.go - GoLand", 30}, span{"general - Acme - Slack", 5}, span{"Inbox - Gmail - Google Chrome", 10}, span{"main.go - GoLand", 15})
+	sc := DayScorecard(snaps)
+	want := Scorecard{
+		TotalActive:   time.Hour,
+		DeepWork:      30 * time.Minute,
+		Switches:      3,
+		LongestStreak: Block{Start: testStart, End: testStart.Add(30 * time.Minute), App: "GoLand"},
+		SwitchRate:    3,
+		Entropy:       AppEntropy(snaps),
+		TopApp:        "GoLand",
+	}
+	if sc != want {
+		t.Errorf("DayScorecard = %+v, want %+v", sc, want)
+	}
+
+	// Each field matches the function it comes from.
+	var deepWork time.Duration
+	for _, d := range DeepWorkByDay(snaps, time.Local, DeepWorkBlock) {
+		deepWork += d
+	}
+	if sc.TotalActive != TotalActive(snaps) || sc.DeepWork != deepWork || sc.Switches != CountSwitches(snaps) || sc.LongestStreak != longestBlock(FocusBlocks(snaps)) {
+		t.Errorf("DayScorecard = %+v, doesn't match TotalActive %s, DeepWorkByDay %s, CountSwitches %d, and FocusBlocks %+v", sc, TotalActive(snaps), deepWork, CountSwitches(snaps), longestBlock(FocusBlocks(snaps)))
+	}
+	if top := sortByTime(AggregateByApp(snaps))[0].App; sc.TopApp != top {
+		t.Errorf("DayScorecard top app = %q, want %q from AggregateByApp", sc.TopApp, top)
+	}
+	if sc.Entropy <= 0 {
+		t.Errorf("DayScorecard entropy = %g, want positive for several apps", sc.Entropy)
+	}
+}
@@ -0,0 +1,119 @@
+package thyme
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// NoDomain is the label given by AggregateByDomain to browser time
+// during which no domain could be recognized in the window name.
+const NoDomain = "(no domain)"
+
+// domainRx matches tokens that look like domain names.
+var domainRx = regexp.MustCompile(`^(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,}$`)
+
+// multiLabelSuffixes are common public suffixes consisting of more than
+// one label, under which registrable domains have three labels rather
+// than two. It is a small subset of the Public Suffix List, covering the
+// suffixes most likely to show up in browser titles.
+var multiLabelSuffixes = map[string]bool{
+	"co.uk":         true,
+	"org.uk":        true,
+	"ac.uk":         true,
+	"gov.uk":        true,
+	"com.au":        true,
+	"net.au":        true,
+	"org.au":        true,
+	"co.jp":         true,
+	"co.nz":         true,
+	"co.in":         true,
+	"com.br":        true,
+	"com.cn":        true,
+	"com.mx":        true,
+	"com.tr":        true,
+	"co.kr":         true,
+	"co.id":         true,
+	"co.za":         true,
+	"github.io":     true,
+	"gitlab.io":     true,
+	"herokuapp.com": true,
+	"netlify.app":   true,
+	"vercel.app":    true,
+	"appspot.com":   true,
+	"blogspot.com":  true,
+}
+
+// EffectiveTLDPlusOne, if set, returns the registrable domain of a
+// domain name, as golang.org/x/net/publicsuffix.EffectiveTLDPlusOne does.
+// Setting it to that function makes AggregateByDomain use the full Public
+// Suffix List rather than multiLabelSuffixes. If it returns an error, the
+// built-in list is used instead.
+var EffectiveTLDPlusOne func(domain string) (string, error)
+
+// registrableDomain returns the registrable domain (the public suffix
+// plus one label, e.g., "github.com" for "gist.github.com") of host.
+func registrableDomain(host string) string {
+	if EffectiveTLDPlusOne != nil {
+		if domain, err := EffectiveTLDPlusOne(host); err == nil {
+			return domain
+		}
+	}
+	labels := strings.Split(host, ".")
+	n := 2
+	if len(labels) >= 3 && multiLabelSuffixes[strings.Join(labels[len(labels)-2:], ".")] {
+		n = 3
+	}
+	if len(labels) < n {
+		return host
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
+
+// windowDomain returns the registrable domain of the first domain-looking
+// token in the SubApp or, failing that, the Title of info, or "" if
+// there is none. URLs are reduced to their host.
+func windowDomain(info *Winfo) string {
+	for _, s := range []string{info.SubApp, info.Title} {
+		for _, token := range strings.Fields(s) {
+			token = strings.ToLower(token)
+			if i := strings.Index(token, "://"); i > -1 {
+				token = token[i+len("://"):]
+			}
+			if i := strings.IndexAny(token, "/:?#"); i > -1 {
+				token = token[:i]
+			}
+			token = strings.TrimPrefix(strings.Trim(token, `()[]<>"',;|`), "www.")
+			if domainRx.MatchString(token) {
+				return registrableDomain(token)
+			}
+		}
+	}
+	return ""
+}
+
+// AggregateByDomain returns the total time each web site was active in a
+// browser, keyed by the registrable domain recognized in the window's
+// SubApp or Title (so that, e.g., "gist.github.com" counts toward
+// "github.com"). Browser time without a recognizable domain is labeled
+// NoDomain. Registrable domains are determined by EffectiveTLDPlusOne,
+// if set, or else heuristically, from a small built-in list of public
+// suffixes.
+func AggregateByDomain(snaps []*Snapshot) map[string]time.Duration {
+	totals := make(map[string]time.Duration)
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		if snap.activeApp() == "" {
+			return
+		}
+		info := snap.ActiveWindow().Info()
+		if !info.IsBrowser() {
+			return
+		}
+		domain := windowDomain(info)
+		if domain == "" {
+			domain = NoDomain
+		}
+		totals[domain] += end.Sub(start)
+	})
+	return totals
+}
@@ -0,0 +1,41 @@
+package thyme
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegistrableDomain(t *testing.T) {
+	tests := []struct {
+		host, want string
+	}{
+		{"github.com", "github.com"},
+		{"gist.github.com", "github.com"},
+		{"news.bbc.co.uk", "bbc.co.uk"},
+		{"www.example.com.mx", "example.com.mx"},
+		{"elanq.github.io", "elanq.github.io"},
+		{"localhost", "localhost"},
+	}
+	for _, test := range tests {
+		if got := registrableDomain(test.host); got != test.want {
+			t.Errorf("registrableDomain(%q) = %q, want %q", test.host, got, test.want)
+		}
+	}
+}
+
+func TestEffectiveTLDPlusOne(t *testing.T) {
+	defer func() { EffectiveTLDPlusOne = nil }()
+	EffectiveTLDPlusOne = func(domain string) (string, error) {
+		if strings.HasSuffix(domain, ".example") {
+			return "", errors.New("unknown suffix")
+		}
+		return "site.test", nil
+	}
+	if got := registrableDomain("a.b.com"); got != "site.test" {
+		t.Errorf("registrableDomain = %q, want the result of EffectiveTLDPlusOne", got)
+	}
+	if got := registrableDomain("a.b.example"); got != "b.example" {
+		t.Errorf("registrableDomain = %q when EffectiveTLDPlusOne fails, want the built-in result", got)
+	}
+}
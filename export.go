@@ -0,0 +1,166 @@
+package thyme
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WriteFolded writes the active time of snaps to w in the folded stack
+// format used by flamegraph.pl. Each line has the form
+// "App;SubApp;Title seconds", with empty frames omitted, and there is
+// one line per distinct window (as identified by its Winfo).
+func WriteFolded(w io.Writer, snaps []*Snapshot) error {
+	totals := make(map[string]time.Duration)
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		if snap.activeApp() == "" {
+			return
+		}
+		info := snap.ActiveWindow().Info()
+		var frames []string
+		for _, f := range []string{canonicalApp(info.App), info.SubApp, info.Title} {
+			if f != "" {
+				frames = append(frames, strings.Replace(f, ";", ":", -1))
+			}
+		}
+		totals[strings.Join(frames, ";")] += end.Sub(start)
+	})
+
+	stacks := make([]string, 0, len(totals))
+	for stack := range totals {
+		stacks = append(stacks, stack)
+	}
+	sort.Strings(stacks)
+	for _, stack := range stacks {
+		seconds := int64(totals[stack] / time.Second)
+		if seconds == 0 || stack == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s %d\n", stack, seconds); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// traceEvent is an event of the Trace Event Format read by
+// chrome://tracing and Perfetto.
+type traceEvent struct {
+	Name  string            `json:"name"`
+	Phase string            `json:"ph"`
+	TS    int64             `json:"ts"`
+	Dur   int64             `json:"dur,omitempty"`
+	PID   int               `json:"pid"`
+	TID   int               `json:"tid"`
+	Args  map[string]string `json:"args,omitempty"`
+}
+
+// WriteChromeTrace writes the active windows of snaps to w as a trace in
+// the Trace Event Format, which can be opened in chrome://tracing or
+// Perfetto. Each contiguous period during which the same window (as
+// identified by its application and title) was active is a complete
+// event named "App/Title", with its timestamp and duration in
+// microseconds, and each application gets its own thread.
+func WriteChromeTrace(w io.Writer, snaps []*Snapshot) error {
+	events := []traceEvent{}
+	tids := make(map[string]int)
+	last := -1 // index of the last complete event, if it may be extended
+	var lastEnd time.Time
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		app := snap.activeApp()
+		if app == "" {
+			last = -1
+			return
+		}
+		name := app + "/" + snap.ActiveWindow().Info().Title
+		if last > -1 && events[last].Name == name && lastEnd.Equal(start) {
+			lastEnd = end
+			events[last].Dur = microseconds(end) - events[last].TS
+			return
+		}
+		tid, ok := tids[app]
+		if !ok {
+			tid = len(tids) + 1
+			tids[app] = tid
+			events = append(events, traceEvent{Name: "thread_name", Phase: "M", PID: 1, TID: tid, Args: map[string]string{"name": app}})
+		}
+		events = append(events, traceEvent{Name: name, Phase: "X", TS: microseconds(start), Dur: microseconds(end) - microseconds(start), PID: 1, TID: tid})
+		last, lastEnd = len(events)-1, end
+	})
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"traceEvents":     events,
+		"displayTimeUnit": "ms",
+	})
+}
+
+// microseconds returns t in microseconds since the Unix epoch.
+func microseconds(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Microsecond)
+}
+
+// wakaHeartbeat is a heartbeat in the format of the WakaTime API.
+type wakaHeartbeat struct {
+	Time     float64 `json:"time"`
+	Entity   string  `json:"entity"`
+	Type     string  `json:"type"`
+	Project  string  `json:"project,omitempty"`
+	Category string  `json:"category"`
+}
+
+// WriteWakaHeartbeats writes a WakaTime-style heartbeat to w for each
+// snapshot of snaps with an active application, as one JSON object per
+// line. Each heartbeat has the time of the snapshot (in seconds since
+// the Unix epoch), the Title of the active window as its entity, its
+// SubApp as its project, and the Category of its application.
+func WriteWakaHeartbeats(w io.Writer, snaps []*Snapshot) error {
+	enc := json.NewEncoder(w)
+	for _, snap := range snaps {
+		if snap.activeApp() == "" {
+			continue
+		}
+		info := snap.ActiveWindow().Info()
+		hb := wakaHeartbeat{
+			Time:     float64(snap.Time.UnixNano()) / float64(time.Second),
+			Entity:   info.Title,
+			Type:     "app",
+			Project:  info.SubApp,
+			Category: info.Category(),
+		}
+		if err := enc.Encode(hb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// promLabelEscaper escapes Prometheus label values.
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePrometheus writes statistics of snaps to w in the Prometheus text
+// exposition format: the gauge thyme_app_seconds, with the active time
+// of each application (see AggregateByApp) labeled by app, and the
+// counter thyme_switches_total, with the number of application switches
+// (see CountSwitches).
+func WritePrometheus(w io.Writer, snaps []*Snapshot) error {
+	totals := AggregateByApp(snaps)
+	apps := make([]string, 0, len(totals))
+	for app := range totals {
+		apps = append(apps, app)
+	}
+	sort.Strings(apps)
+
+	var b strings.Builder
+	b.WriteString("# HELP thyme_app_seconds Time each application was active, in seconds.\n")
+	b.WriteString("# TYPE thyme_app_seconds gauge\n")
+	for _, app := range apps {
+		fmt.Fprintf(&b, "thyme_app_seconds{app=\"%s\"} %g\n", promLabelEscaper.Replace(app), totals[app].Seconds())
+	}
+	b.WriteString("# HELP thyme_switches_total Number of switches between applications.\n")
+	b.WriteString("# TYPE thyme_switches_total counter\n")
+	fmt.Fprintf(&b, "thyme_switches_total %d\n", CountSwitches(snaps))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,117 @@
+package thyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteFolded(t *testing.T) {
+	snaps := spans(span{"Inbox - Gmail - Google Chrome", 10}, span{"main.go - GoLand", 5}, span{"Inbox - Gmail - Google Chrome", 2})
+	var b bytes.Buffer
+	if err := WriteFolded(&b, snaps); err != nil {
+		t.Fatal(err)
+	}
+	// The application without a SubApp has no empty frame.
+	const want = "GoLand;main.go 300\nGoogle Chrome;Gmail;Inbox 720\n"
+	if got := b.String(); got != want {
+		t.Errorf("WriteFolded wrote %q, want %q", got, want)
+	}
+}
+
+func TestWriteChromeTrace(t *testing.T) {
+	snaps := spans(span{"main.go - GoLand", 10}, span{"Inbox - Gmail - Google Chrome", 5}, span{"", 2}, span{"main.go - GoLand", 3})
+	var b bytes.Buffer
+	if err := WriteChromeTrace(&b, snaps); err != nil {
+		t.Fatal(err)
+	}
+	var trace struct {
+		TraceEvents []traceEvent
+	}
+	if err := json.Unmarshal(b.Bytes(), &trace); err != nil {
+		t.Fatalf("WriteChromeTrace wrote invalid JSON: %s\n%s", err, b.String())
+	}
+	us := func(m int) int64 { return microseconds(testStart.Add(time.Duration(m) * time.Minute)) }
+	want := []traceEvent{
+		{Name: "GoLand/main.go", Phase: "X", TS: us(0), Dur: us(10) - us(0), PID: 1, TID: 1},
+		{Name: "Google Chrome/Inbox", Phase: "X", TS: us(10), Dur: us(15) - us(10), PID: 1, TID: 2},
+		{Name: "GoLand/main.go", Phase: "X", TS: us(17), Dur: us(20) - us(17), PID: 1, TID: 1},
+	}
+	var got []traceEvent
+	threads := make(map[int]string)
+	for _, e := range trace.TraceEvents {
+		switch e.Phase {
+		case "X":
+			got = append(got, e)
+		case "M":
+			threads[e.TID] = e.Args["name"]
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WriteChromeTrace wrote complete events %+v, want %+v", got, want)
+	}
+	if want := map[int]string{1: "GoLand", 2: "Google Chrome"}; !reflect.DeepEqual(threads, want) {
+		t.Errorf("WriteChromeTrace named threads %v, want %v", threads, want)
+	}
+}
+
+func TestWriteWakaHeartbeats(t *testing.T) {
+	snaps := session(30*time.Second, "main.go - thyme - Visual Studio Code", "", "Inbox - Gmail - Google Chrome")
+	var b bytes.Buffer
+	if err := WriteWakaHeartbeats(&b, snaps); err != nil {
+		t.Fatal(err)
+	}
+	epoch := float64(testStart.Unix())
+	want := []wakaHeartbeat{
+		{Time: epoch, Entity: "main.go", Type: "app", Project: "thyme", Category: DevelopmentCategory},
+		{Time: epoch + 60, Entity: "Inbox", Type: "app", Project: "Gmail", Category: BrowserCategory},
+	}
+	var got []wakaHeartbeat
+	dec := json.NewDecoder(&b)
+	for dec.More() {
+		var hb wakaHeartbeat
+		if err := dec.Decode(&hb); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, hb)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WriteWakaHeartbeats wrote %+v, want %+v", got, want)
+	}
+}
+
+// promLineRx matches the comment and sample lines of the Prometheus text
+// exposition format.
+var promLineRx = regexp.MustCompile(`^(?:# (?:HELP|TYPE) [a-z_]+ .+|([a-z_]+)(?:\{app="((?:[^"\\]|\\.)*)"\})? ([0-9.e+]+))$`)
+
+func TestWritePrometheus(t *testing.T) {
+	snaps := spans(span{"main.go - GoLand", 10}, span{`notes - Say "Hi"`, 5}, span{"general - Acme - Slack", 2})
+	var b bytes.Buffer
+	if err := WritePrometheus(&b, snaps); err != nil {
+		t.Fatal(err)
+	}
+	apps := make(map[string]string)
+	switches := ""
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+		m := promLineRx.FindStringSubmatch(line)
+		switch {
+		case m == nil:
+			t.Errorf("invalid exposition line %q", line)
+		case m[1] == "thyme_app_seconds":
+			if _, dup := apps[m[2]]; dup {
+				t.Errorf("more than one line for app %q", m[2])
+			}
+			apps[m[2]] = m[3]
+		case m[1] == "thyme_switches_total":
+			switches = m[3]
+		}
+	}
+	want := map[string]string{"GoLand": "600", `Say \"Hi\"`: "300", "Slack": "120"}
+	if !reflect.DeepEqual(apps, want) || switches != "2" {
+		t.Errorf("WritePrometheus wrote app seconds %v and %s switches, want %v and 2:\n%s", apps, switches, want, b.String())
+	}
+}
@@ -0,0 +1,31 @@
+package thyme
+
+import "time"
+
+// IdleDetector reports how long the user has been idle (i.e., hasn't
+// provided any keyboard or mouse input). Implementations are platform
+// and setup specific; XprintidleDetector is provided for X11.
+type IdleDetector interface {
+	IdleTime() (time.Duration, error)
+}
+
+// IdleThreshold is the idle time after which a snapshot is considered
+// idle (see Snapshot.IsIdle).
+var IdleThreshold = 5 * time.Minute
+
+// IsIdle returns true if the user had been idle for at least
+// IdleThreshold when the snapshot was taken. Idle snapshots don't count
+// towards the time of their active window.
+func (s Snapshot) IsIdle() bool {
+	return s.Idle >= IdleThreshold
+}
+
+// activeApp returns the application key of the snapshot's active
+// window, or "" if there is no active application window or the
+// snapshot is idle.
+func (s Snapshot) activeApp() string {
+	if s.IsIdle() {
+		return ""
+	}
+	return s.activeAppID()
+}
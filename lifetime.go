@@ -0,0 +1,109 @@
+package thyme
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// DetectIDResets returns the times of the snapshots in which a window ID
+// that had previously disappeared reappears as a window of a different
+// application. This usually means the window manager restarted and began
+// reusing IDs, so windows with the same ID on either side of the reset
+// shouldn't be treated as the same window.
+func DetectIDResets(snaps []*Snapshot) []time.Time {
+	var resets []time.Time
+	// Windows are identified by their X11 ID (see xid), which several
+	// windows of different applications may share.
+	lastApps := make(map[int64]map[string]bool)
+	present := make(map[int64]map[string]bool)
+	for _, snap := range snaps {
+		current := make(map[int64]map[string]bool, len(snap.Windows))
+		for _, w := range snap.Windows {
+			id := xid(w.ID)
+			if current[id] == nil {
+				current[id] = make(map[string]bool)
+			}
+			current[id][appID(w)] = true
+		}
+		reset := false
+		for id, apps := range current {
+			if prev, seen := lastApps[id]; seen && present[id] == nil && !anyApp(prev, apps) {
+				reset = true
+			}
+			lastApps[id] = apps
+		}
+		if reset {
+			resets = append(resets, snap.Time)
+		}
+		present = current
+	}
+	return resets
+}
+
+// anyApp returns true if a and b have an application in common.
+func anyApp(a, b map[string]bool) bool {
+	for app := range a {
+		if b[app] {
+			return true
+		}
+	}
+	return false
+}
+
+// Lifetime is the period during which a window existed with a given
+// name. A window whose name changes starts a new lifetime.
+type Lifetime struct {
+	ID   int64
+	Name string
+	Info *Winfo
+
+	// FirstSeen and LastSeen are the times of the first and last
+	// snapshots that include the window.
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// lifetimeKey identifies the lifetime of a window. Windows are
+// identified by their X11 ID (see xid), since a synthesized ID changes
+// when other windows stop sharing the X11 ID, and their name.
+type lifetimeKey struct {
+	id   int64
+	name string
+}
+
+// WindowLifetimes returns the lifetimes of the windows in snaps, ordered
+// by when they started. A lifetime ends once a snapshot no longer
+// includes the window with the same ID and name.
+func WindowLifetimes(snaps []*Snapshot) []Lifetime {
+	var lifetimes []Lifetime
+	open := make(map[lifetimeKey]int)
+	for _, snap := range snaps {
+		current := make(map[lifetimeKey]int, len(snap.Windows))
+		for _, w := range snap.Windows {
+			k := lifetimeKey{xid(w.ID), w.Name}
+			if _, dup := current[k]; dup {
+				continue
+			}
+			i, ok := open[k]
+			if !ok {
+				i = len(lifetimes)
+				lifetimes = append(lifetimes, Lifetime{ID: w.ID, Name: w.Name, Info: w.Info(), FirstSeen: snap.Time})
+			}
+			lifetimes[i].LastSeen = snap.Time
+			current[k] = i
+		}
+		open = current
+	}
+	return lifetimes
+}
+
+// WriteLifetimesJSON writes the lifetimes of the windows in snaps (see
+// WindowLifetimes) to w as a JSON array.
+func WriteLifetimesJSON(w io.Writer, snaps []*Snapshot) error {
+	lifetimes := WindowLifetimes(snaps)
+	if lifetimes == nil {
+		lifetimes = []Lifetime{}
+	}
+	return json.NewEncoder(w).Encode(lifetimes)
+}
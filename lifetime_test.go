@@ -0,0 +1,92 @@
+package thyme
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWindowLifetimes(t *testing.T) {
+	// Two windows share an X11 ID until one of them is closed, after
+	// which the other reverts to the X11 ID.
+	outputs := []string{
+		"0x06400001 0 host Main\n0x06400001 0 host Settings\n",
+		"0x06400001 0 host Settings\n0x06400001 0 host Main\n",
+		"0x06400001 0 host Main\n",
+		"0x06400001 0 host Main - edited\n",
+	}
+	var snaps []*Snapshot
+	for i, out := range outputs {
+		snap, err := ParseX11(out, "104857601\n", "0  * DG: 1920x1080\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+		snap.Time = testStart.Add(time.Duration(i) * time.Minute)
+		snaps = append(snaps, snap)
+	}
+
+	var got []string
+	for _, l := range WindowLifetimes(snaps) {
+		got = append(got, l.Name+" "+l.FirstSeen.Sub(testStart).String()+"-"+l.LastSeen.Sub(testStart).String())
+	}
+	want := []string{"Main 0s-2m0s", "Settings 0s-1m0s", "Main - edited 3m0s-3m0s"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WindowLifetimes = %q, want %q", got, want)
+	}
+	if resets := DetectIDResets(snaps); len(resets) != 0 {
+		t.Errorf("DetectIDResets = %v, want none", resets)
+	}
+}
+
+func TestDetectIDResets(t *testing.T) {
+	snaps := []*Snapshot{
+		{Time: testStart, Windows: []*Window{{ID: 1, Name: "main.go - GoLand"}, {ID: 2, Name: "general - Acme - Slack"}}},
+		{Time: testStart.Add(time.Minute), Windows: []*Window{{ID: 2, Name: "general - Acme - Slack"}}},
+		{Time: testStart.Add(2 * time.Minute), Windows: []*Window{{ID: 1, Name: "Inbox - Gmail - Google Chrome"}}},
+	}
+	want := []time.Time{testStart.Add(2 * time.Minute)}
+	if got := DetectIDResets(snaps); !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectIDResets = %v, want %v", got, want)
+	}
+}
+
+func TestWriteLifetimesJSON(t *testing.T) {
+	snaps := []*Snapshot{
+		{Time: testStart, Windows: []*Window{{ID: 1, Name: "main.go - GoLand"}, {ID: 2, Name: "general - Acme - Slack"}}},
+		{Time: testStart.Add(time.Minute), Windows: []*Window{{ID: 2, Name: "general - Acme - Slack"}}},
+		{Time: testStart.Add(2 * time.Minute), Windows: []*Window{{ID: 1, Name: "Inbox - Gmail - Google Chrome"}}},
+	}
+	var b bytes.Buffer
+	if err := WriteLifetimesJSON(&b, snaps); err != nil {
+		t.Fatal(err)
+	}
+	var got []Lifetime
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatalf("WriteLifetimesJSON wrote invalid JSON: %s\n%s", err, b.String())
+	}
+	want := []struct {
+		id                  int64
+		app                 string
+		firstSeen, lastSeen time.Duration
+	}{
+		{1, "GoLand", 0, 0},
+		{2, "Slack", 0, time.Minute},
+		{1, "Google Chrome", 2 * time.Minute, 2 * time.Minute},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("WriteLifetimesJSON wrote %d lifetimes, want %d:\n%s", len(got), len(want), b.String())
+	}
+	for i, l := range got {
+		w := want[i]
+		if l.ID != w.id || l.Info == nil || l.Info.App != w.app || !l.FirstSeen.Equal(testStart.Add(w.firstSeen)) || !l.LastSeen.Equal(testStart.Add(w.lastSeen)) {
+			t.Errorf("lifetime %d = %+v, want window %d of %s seen from %s to %s", i, l, w.id, w.app, testStart.Add(w.firstSeen), testStart.Add(w.lastSeen))
+		}
+	}
+
+	b.Reset()
+	if err := WriteLifetimesJSON(&b, nil); err != nil || b.String() != "[]\n" {
+		t.Errorf("WriteLifetimesJSON of no snapshots wrote %q (error: %v), want an empty array", b.String(), err)
+	}
+}
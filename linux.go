@@ -1,9 +1,12 @@
+//go:build !js
+// +build !js
+
 package thyme
 
 import (
 	"fmt"
+	"log"
 	"os/exec"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -14,7 +17,11 @@ func init() {
 }
 
 // LinuxTracker tracks application usage on Linux via a few standard command-line utilities.
-type LinuxTracker struct{}
+type LinuxTracker struct {
+	// warnedXrandr is set once a failure to run xrandr has been
+	// reported, so that it isn't reported for every snapshot.
+	warnedXrandr bool
+}
 
 var _ Tracker = (*LinuxTracker)(nil)
 
@@ -27,11 +34,14 @@ func (t *LinuxTracker) Deps() string {
 Install the following command-line utilities via your package manager of choice:
 * xdpyinfo
 * xwininfo
+* xprop
 * xdotool
 * wmctrl
+* xprintidle (optional, used to detect idle time)
+* xrandr (optional, used to detect which monitor windows are on)
 
 For example:
-* Debian: apt-get install x11-utils xdotool wmctrl
+* Debian: apt-get install x11-utils xdotool wmctrl xprintidle x11-xserver-utils
 
 Note: this command prints out this message regardless of whether the dependencies are already installed.
 `
@@ -59,63 +69,71 @@ func (t *LinuxTracker) Snap() (*Snapshot, error) {
 		viewWidth, viewHeight = w, h
 	}
 
-	var windows []*Window
+	var wmctrlOut, desktopOut, activeOut string
 	{
 		out, err := exec.Command("wmctrl", "-l").Output()
 		if err != nil {
 			return nil, fmt.Errorf("wmctrl failed with error: %s. Try running `wmctrl -l` to diagnose.", err)
 		}
-		lines := strings.Split(string(out), "\n")
-		for _, line := range lines {
-			fields := strings.Fields(line)
-			if len(fields) < 4 {
-				continue
-			}
-			id_, desktop_, name := fields[0], fields[1], strings.Join(fields[3:], " ")
-			id, err := strconv.ParseInt(id_, 0, 64)
-			if err != nil {
-				return nil, err
-			}
-			desktop, err := strconv.ParseInt(desktop_, 0, 64)
-			if err != nil {
-				return nil, err
-			}
-			w := Window{ID: id, Desktop: desktop, Name: name}
-			if !w.IsSystem() {
-				windows = append(windows, &w)
-			}
-		}
+		wmctrlOut = string(out)
 	}
-
-	var currentDesktop int64
 	{
 		out, err := exec.Command("wmctrl", "-d").Output()
 		if err != nil {
 			return nil, err
 		}
-		lines := strings.Split(string(out), "\n")
-		for _, line := range lines {
-			fields := strings.Fields(line)
-			if len(fields) < 2 {
-				continue
-			}
-			id_, mode := fields[0], fields[1]
-			id, err := strconv.ParseInt(id_, 0, 64)
-			if err != nil {
-				return nil, err
-			}
-			if "*" == mode {
-				currentDesktop = id
-			}
+		desktopOut = string(out)
+	}
+	{
+		out, err := exec.Command("xdotool", "getactivewindow").Output()
+		if err != nil {
+			return nil, fmt.Errorf("xdotool failed with error: %s. Try running `xdotool getactivewindow` to diagnose.", err)
 		}
+		activeOut = string(out)
+
+		// The active window's name disambiguates windows that share its
+		// ID (see ParseX11).
+		if name, err := exec.Command("xdotool", "getwindowname", strings.TrimSpace(activeOut)).Output(); err == nil {
+			activeOut += string(name)
+		}
+	}
+	snap, err := ParseX11(wmctrlOut, activeOut, desktopOut)
+	if err != nil {
+		return nil, err
+	}
+	snap.Time = time.Now()
+
+	// On reparenting window managers, the active window may be the frame
+	// of a listed client window or vice versa, so search its descendants
+	// and ancestors for a listed window. This is best-effort: the active
+	// window may have been closed in the meantime, in which case it is
+	// left as is.
+	for id, depth := snap.Active, 0; snap.window(snap.Active) == nil && id != 0 && depth < maxTreeDepth; depth++ {
+		out, err := exec.Command("xwininfo", "-tree", "-id", fmt.Sprintf("%d", id)).Output()
+		if err != nil {
+			break
+		}
+		parent, children := parseWindowTree(string(out))
+		if depth > 0 {
+			// Only ancestors are related to the active window above the
+			// first level.
+			children = nil
+		}
+		snap.reconcileActive(parent, children)
+		id = parent
 	}
 
-	var visible []int64
 	{
-		for _, window := range windows {
-			out_, err := exec.Command("xwininfo", "-id", fmt.Sprintf("%d", window.ID), "-stats").Output()
+		// An empty (rather than nil) Visible tells a snapshot in which
+		// no window was visible apart from one whose visible windows
+		// are unknown.
+		snap.Visible = []int64{}
+		for _, window := range snap.Windows {
+			out_, err := exec.Command("xwininfo", "-id", fmt.Sprintf("%d", xid(window.ID)), "-stats").Output()
 			if err != nil {
-				return nil, fmt.Errorf("xwininfo failed with error: %s", err)
+				// The window was likely closed since it was listed, so
+				// its geometry and visibility are unknown.
+				continue
 			}
 			out := string(out_)
 			x, err := parseWinDim(xRx, out, "X")
@@ -134,57 +152,55 @@ func (t *LinuxTracker) Snap() (*Snapshot, error) {
 			if err != nil {
 				return nil, err
 			}
-			if window.IsOnDesktop(currentDesktop) && isVisible(x, y, w, h, viewHeight, viewWidth) {
-				visible = append(visible, window.ID)
+			window.Geometry = &Rect{X: x, Y: y, Width: w, Height: h}
+			if window.IsOnDesktop(snap.Desktop) && isVisible(x, y, w, h, viewHeight, viewWidth) {
+				snap.Visible = append(snap.Visible, window.ID)
 			}
 		}
 	}
 
-	var active int64
 	{
-		out, err := exec.Command("xdotool", "getactivewindow").Output()
-		if err != nil {
-			return nil, fmt.Errorf("xdotool failed with error: %s. Try running `xdotool getactivewindow` to diagnose.", err)
+		for _, window := range snap.Windows {
+			out, err := exec.Command("xprop", "-id", fmt.Sprintf("%d", xid(window.ID)), "WM_CLASS", "_NET_WM_WINDOW_OPACITY", "_NET_WM_WINDOW_TYPE").Output()
+			if err != nil {
+				return nil, fmt.Errorf("xprop failed with error: %s", err)
+			}
+			window.Class = parseWMClass(string(out))
+			window.Opacity = parseOpacity(string(out))
+			window.Type = parseWindowType(string(out))
 		}
-		id, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	}
+
+	{
+		out, err := exec.Command("xrandr", "--query").Output()
 		if err != nil {
-			return nil, err
+			if !t.warnedXrandr {
+				log.Printf("warning: xrandr failed with error: %s, monitors will not be recorded", err)
+				t.warnedXrandr = true
+			}
+		} else {
+			snap.Outputs = parseXrandr(string(out))
 		}
-		active = id
 	}
 
-	return &Snapshot{Windows: windows, Active: active, Visible: visible, Time: time.Now()}, nil
+	snap.assignOutputs()
+	return snap, nil
 }
 
-// isVisible checks if the window is visible in the current viewport.
-// x and y are assumed to be relative to the current viewport (i.e.,
-// (0, 0) is the coordinate of the top-left corner of the current
-// viewport.
-func isVisible(x, y, w, h, viewHeight, viewWidth int) bool {
-	return (0 <= x && x < viewWidth && 0 <= y && y < viewHeight) ||
-		(0 <= x+w && x+w < viewWidth && 0 <= y && y < viewHeight) ||
-		(0 <= x && x < viewWidth && 0 <= y+h && y+h < viewHeight) ||
-		(0 <= x+w && x+w < viewWidth && 0 <= y+h && y+h < viewHeight)
-}
+// XprintidleDetector is an IdleDetector that uses the xprintidle
+// command-line utility to read the X11 idle time.
+type XprintidleDetector struct{}
 
-var (
-	dimRx = regexp.MustCompile(`dimensions:\s+([0-9]+)x([0-9]+)\s+pixels`)
-	xRx   = regexp.MustCompile(`Absolute upper\-left X:\s+(\-?[0-9]+)`)
-	yRx   = regexp.MustCompile(`Absolute upper\-left Y:\s+(\-?[0-9]+)`)
-	wRx   = regexp.MustCompile(`Width:\s+([0-9]+)`)
-	hRx   = regexp.MustCompile(`Height:\s+([0-9]+)`)
-)
+var _ IdleDetector = XprintidleDetector{}
 
-// parseWinDim parses window dimension info from the output of `xwininfo`
-func parseWinDim(rx *regexp.Regexp, out string, varname string) (int, error) {
-	if matches := rx.FindStringSubmatch(out); len(matches) == 2 {
-		n, err := strconv.Atoi(matches[1])
-		if err != nil {
-			return 0, err
-		}
-		return n, nil
-	} else {
-		return 0, fmt.Errorf("could not parse window %s from output %s", varname, out)
+func (XprintidleDetector) IdleTime() (time.Duration, error) {
+	out, err := exec.Command("xprintidle").Output()
+	if err != nil {
+		return 0, fmt.Errorf("xprintidle failed with error: %s. Try running `xprintidle` to diagnose.", err)
 	}
-
+	ms, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ms) * time.Millisecond, nil
 }
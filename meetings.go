@@ -0,0 +1,77 @@
+package thyme
+
+import "time"
+
+// videoCallApps is a set of video-call applications. Names of web
+// applications (e.g., "Google Meet") match the SubApp of browser windows.
+var videoCallApps = map[string]struct{}{
+	"Zoom":            {},
+	"zoom.us":         {},
+	"Microsoft Teams": {},
+	"Google Meet":     {},
+	"Meet":            {},
+	"Webex":           {},
+	"Skype":           {},
+}
+
+// RegisterVideoCallApp registers the name of a video-call application
+// (or a web application running in a browser), so that time spent in it
+// counts as meetings.
+func RegisterVideoCallApp(app string) {
+	videoCallApps[app] = struct{}{}
+}
+
+// videoCallApp returns the video-call application of the active window
+// of the snapshot, or "" if it isn't one.
+func (s Snapshot) videoCallApp() string {
+	if s.activeApp() == "" {
+		return ""
+	}
+	info := s.ActiveWindow().Info()
+	if _, ok := videoCallApps[canonicalApp(info.App)]; ok {
+		return canonicalApp(info.App)
+	}
+	if _, ok := videoCallApps[info.SubApp]; ok && info.IsBrowser() {
+		return info.SubApp
+	}
+	return ""
+}
+
+// Meetings returns the periods during which a video-call application was
+// active, in order. Periods in the same application separated by at
+// most gap (e.g., while briefly checking another window during a call)
+// are merged into one meeting, while calls in different applications or
+// further apart are separate meetings. Meetings spanning midnight in loc
+// are split at midnight, so that each meeting belongs to a single day.
+func Meetings(snaps []*Snapshot, loc *time.Location, gap time.Duration) []Block {
+	var calls []Block
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		app := snap.videoCallApp()
+		if app == "" {
+			return
+		}
+		if n := len(calls); n > 0 && calls[n-1].App == app && start.Sub(calls[n-1].End) <= gap {
+			calls[n-1].End = end
+			return
+		}
+		calls = append(calls, Block{Start: start, End: end, App: app})
+	})
+
+	var meetings []Block
+	for _, c := range calls {
+		splitInterval(c.Start, c.End, nextDay(loc), func(start, end time.Time) {
+			meetings = append(meetings, Block{Start: start, End: end, App: c.App})
+		})
+	}
+	return meetings
+}
+
+// MeetingTimeByDay returns the total duration of meetings (see Meetings)
+// on each day in loc, keyed by its date (e.g., "2006-01-02").
+func MeetingTimeByDay(snaps []*Snapshot, loc *time.Location, gap time.Duration) map[string]time.Duration {
+	totals := make(map[string]time.Duration)
+	for _, m := range Meetings(snaps, loc, gap) {
+		totals[m.Start.In(loc).Format(dayFormat)] += m.Duration()
+	}
+	return totals
+}
@@ -0,0 +1,25 @@
+package thyme
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMeetings(t *testing.T) {
+	at := func(m int) time.Time { return testStart.Add(time.Duration(m) * time.Minute) }
+	// A call from 9:30, during which the user checks their email, and
+	// another call in a browser 10 minutes after the first ends.
+	snaps := spans(span{"main.go - GoLand", 30}, span{"Standup - Zoom", 20}, span{"Inbox - Gmail - Google Chrome", 1}, span{"Standup - Zoom", 10},
+		span{"main.go - GoLand", 10}, span{"Planning - Google Meet - Google Chrome", 15})
+	want := []Block{
+		{Start: at(30), End: at(61), App: "Zoom"},
+		{Start: at(71), End: at(86), App: "Google Meet"},
+	}
+	if got := Meetings(snaps, time.UTC, 2*time.Minute); !reflect.DeepEqual(got, want) {
+		t.Errorf("Meetings = %+v, want %+v", got, want)
+	}
+	if got, want := MeetingTimeByDay(snaps, time.UTC, 2*time.Minute), map[string]time.Duration{"2026-03-02": 46 * time.Minute}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MeetingTimeByDay = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,182 @@
+package thyme
+
+import (
+	"sort"
+	"time"
+)
+
+// MergeExclusive merges two recordings made at the same time (e.g., on
+// two machines) into a single timeline. Each interval of time is
+// attributed to the primary recording while it has an active
+// application, and otherwise to the secondary recording, if it covers
+// the interval, or else to the primary recording. Where the secondary
+// recording resumes in the middle of one of its intervals, a copy of
+// its snapshot is inserted with the time it resumes, and stretches that
+// neither recording covers are marked by empty snapshots. Since the
+// merged snapshots form a single timeline, the total time attributed to
+// applications never exceeds the wall-clock time covered.
+func MergeExclusive(primary, secondary []*Snapshot) []*Snapshot {
+	times := make([]time.Time, 0, len(primary)+len(secondary))
+	for _, snap := range primary {
+		times = append(times, snap.Time)
+	}
+	for _, snap := range secondary {
+		times = append(times, snap.Time)
+	}
+	sort.Slice(times, func(a, b int) bool { return times[a].Before(times[b]) })
+
+	claimed := make([]bool, len(primary))
+	for i, snap := range primary {
+		claimed[i] = snap.activeApp() != ""
+	}
+
+	merged := make([]*Snapshot, 0, len(times))
+	var last *Snapshot // the snapshot governing the last interval
+	for k, t := range times {
+		if k > 0 && times[k-1].Equal(t) {
+			continue
+		}
+		p, pCovers := covering(primary, t)
+		s, sCovers := covering(secondary, t)
+		var snap *Snapshot
+		switch {
+		case pCovers && claimed[p]:
+			snap = primary[p]
+		case sCovers:
+			snap = secondary[s]
+		case pCovers:
+			snap = primary[p]
+		case k+1 == len(times):
+			// The end of the timeline.
+			if p >= 0 && primary[p].Time.Equal(t) {
+				snap = primary[p]
+			} else {
+				snap = secondary[s]
+			}
+		default:
+			snap = &Snapshot{Time: t}
+		}
+		if snap == last {
+			continue
+		}
+		last = snap
+		if !snap.Time.Equal(t) {
+			c := *snap
+			c.Time = t
+			snap = &c
+		}
+		merged = append(merged, snap)
+	}
+	return merged
+}
+
+// covering returns the index of the last snapshot of snaps taken at or
+// before t, or -1 if there is none, and whether that snapshot covers the
+// time after t (i.e., it isn't the last snapshot).
+func covering(snaps []*Snapshot, t time.Time) (int, bool) {
+	i := sort.Search(len(snaps), func(i int) bool { return snaps[i].Time.After(t) }) - 1
+	return i, i >= 0 && i+1 < len(snaps)
+}
+
+// CollapsePhantoms returns snaps with every run of consecutive snapshots
+// that have exactly the same time (which happens when snapshots are
+// taken faster than the clock advances) collapsed into the last snapshot
+// of the run.
+func CollapsePhantoms(snaps []*Snapshot) []*Snapshot {
+	collapsed := make([]*Snapshot, 0, len(snaps))
+	for _, snap := range snaps {
+		if n := len(collapsed); n > 0 && collapsed[n-1].Time.Equal(snap.Time) {
+			collapsed[n-1] = snap
+			continue
+		}
+		collapsed = append(collapsed, snap)
+	}
+	return collapsed
+}
+
+// Conflict is a period during which two recordings both had an active
+// application.
+type Conflict struct {
+	Start, End time.Time
+
+	// A and B are the applications active in each recording.
+	A, B string
+}
+
+// OverlapConflicts returns the periods, in order, during which both
+// recordings a and b had an active application, as happens when two
+// recordings made on different machines are merged while both were in
+// use. Adjacent periods with the same applications are reported as one.
+func OverlapConflicts(a, b []*Snapshot) []Conflict {
+	activeA, activeB := FocusBlocks(a), FocusBlocks(b)
+	var conflicts []Conflict
+	for i, j := 0, 0; i < len(activeA) && j < len(activeB); {
+		x, y := activeA[i], activeB[j]
+		start, end := x.Start, x.End
+		if y.Start.After(start) {
+			start = y.Start
+		}
+		if y.End.Before(end) {
+			end = y.End
+		}
+		if start.Before(end) {
+			if n := len(conflicts); n > 0 && conflicts[n-1].End.Equal(start) && conflicts[n-1].A == x.App && conflicts[n-1].B == y.App {
+				conflicts[n-1].End = end
+			} else {
+				conflicts = append(conflicts, Conflict{Start: start, End: end, A: x.App, B: y.App})
+			}
+		}
+		if x.End.Before(y.End) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return conflicts
+}
+
+// CoalesceByFocus returns snaps with every run of consecutive snapshots
+// that have the same active window (with the same name) and the same set
+// of visible windows (and the same idle state) collapsed into the first
+// snapshot of the run, which then covers the time of the whole run.
+// Changes to windows that are neither active nor visible don't end a
+// run.
+func CoalesceByFocus(snaps []*Snapshot) []*Snapshot {
+	coalesced := make([]*Snapshot, 0, len(snaps))
+	for _, snap := range snaps {
+		if n := len(coalesced); n > 0 && sameFocus(coalesced[n-1], snap) {
+			continue
+		}
+		coalesced = append(coalesced, snap)
+	}
+	return coalesced
+}
+
+// sameFocus returns true if a and b have the same active window, with
+// the same name, the same set of visible windows, and are both idle or
+// both not idle.
+func sameFocus(a, b *Snapshot) bool {
+	if a.Active != b.Active || a.IsIdle() != b.IsIdle() || (a.Visible == nil) != (b.Visible == nil) {
+		return false
+	}
+	if wa, wb := a.window(a.Active), b.window(b.Active); (wa == nil) != (wb == nil) || wa != nil && wa.Name != wb.Name {
+		return false
+	}
+	return sameIDs(a.Visible, b.Visible)
+}
+
+// sameIDs returns true if a and b contain the same set of IDs.
+func sameIDs(a, b []int64) bool {
+	inA := make(map[int64]bool, len(a))
+	for _, id := range a {
+		inA[id] = true
+	}
+	inB := make(map[int64]bool, len(b))
+	for _, id := range b {
+		if !inA[id] {
+			return false
+		}
+		inB[id] = true
+	}
+	return len(inA) == len(inB)
+}
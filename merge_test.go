@@ -0,0 +1,115 @@
+package thyme
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeExclusive(t *testing.T) {
+	repeat := func(name string, n int) []string {
+		names := make([]string, n)
+		for i := range names {
+			names[i] = name
+		}
+		return names
+	}
+	tests := []struct {
+		name               string
+		primary, secondary []*Snapshot
+		want               map[string]time.Duration
+	}{{
+		name:      "primary without focus",
+		primary:   session(time.Second, repeat("", 61)...),
+		secondary: session(5*time.Second, repeat("main.go - GoLand", 13)...),
+		want:      map[string]time.Duration{"GoLand": time.Minute},
+	}, {
+		name:      "primary claims the overlap",
+		primary:   session(30*time.Second, "general - Acme - Slack", "", ""),
+		secondary: session(time.Minute, "main.go - GoLand", "main.go - GoLand"),
+		want:      map[string]time.Duration{"Slack": 30 * time.Second, "GoLand": 30 * time.Second},
+	}, {
+		name:      "secondary resumes mid-interval",
+		primary:   session(time.Minute, "general - Acme - Slack", "", "", ""),
+		secondary: session(90*time.Second, "", "main.go - GoLand", "main.go - GoLand"),
+		want:      map[string]time.Duration{"Slack": time.Minute, "GoLand": 90 * time.Second},
+	}}
+	for _, test := range tests {
+		merged := MergeExclusive(test.primary, test.secondary)
+		if got := AggregateByApp(merged); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: merged totals = %v, want %v", test.name, got, test.want)
+		}
+		for i := 1; i < len(merged); i++ {
+			if !merged[i-1].Time.Before(merged[i].Time) {
+				t.Errorf("%s: merged snapshot %d at %s isn't after the previous one at %s", test.name, i, merged[i].Time, merged[i-1].Time)
+			}
+		}
+	}
+}
+
+func TestCollapsePhantoms(t *testing.T) {
+	snaps := spans(span{"Inbox - Gmail - Google Chrome", 10})
+	// A window focused only momentarily, at the same time as the next
+	// snapshot, and a duplicate of the last snapshot.
+	phantom := session(time.Minute, "main.go - GoLand")[0]
+	last := *snaps[len(snaps)-1]
+	snaps = append(append([]*Snapshot{phantom}, snaps...), &last)
+
+	collapsed := CollapsePhantoms(snaps)
+	if len(collapsed) != 11 || collapsed[0] != snaps[1] || collapsed[10] != &last {
+		t.Errorf("CollapsePhantoms kept %d snapshots, want 11, keeping the later of each pair", len(collapsed))
+	}
+	want := map[string]time.Duration{"Google Chrome": 10 * time.Minute}
+	if got := AggregateByApp(snaps); !reflect.DeepEqual(got, want) {
+		t.Errorf("AggregateByApp = %v, want %v", got, want)
+	}
+	if n := CountSwitches(snaps); n != 0 {
+		t.Errorf("CountSwitches = %d, want 0", n)
+	}
+}
+
+func TestOverlapConflicts(t *testing.T) {
+	at := func(m int) time.Time { return testStart.Add(time.Duration(m) * time.Minute) }
+	a := spans(span{"main.go - GoLand", 30}, span{"", 15}, span{"main.go (~/app) - NVIM", 10})
+	// The second recording starts at 9:20.
+	b := spans(span{"Inbox - Gmail - Google Chrome", 20}, span{"general - Acme - Slack", 10})
+	for _, snap := range b {
+		snap.Time = snap.Time.Add(20 * time.Minute)
+	}
+	want := []Conflict{
+		{Start: at(20), End: at(30), A: "GoLand", B: "Google Chrome"},
+		{Start: at(45), End: at(50), A: "Neovim", B: "Slack"},
+	}
+	if got := OverlapConflicts(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("OverlapConflicts = %+v, want %+v", got, want)
+	}
+	if got := OverlapConflicts(a, a[:0]); got != nil {
+		t.Errorf("OverlapConflicts with an empty recording = %+v, want none", got)
+	}
+}
+
+func TestCoalesceByFocus(t *testing.T) {
+	var snaps []*Snapshot
+	for i := 0; i < 6; i++ {
+		snaps = append(snaps, &Snapshot{
+			Time: testStart.Add(time.Duration(i) * time.Minute),
+			Windows: []*Window{
+				{ID: 1, Name: "main.go - GoLand"},
+				{ID: 2, Name: "Inbox - Gmail - Google Chrome"},
+				// Only the background window changes.
+				{ID: 3, Name: fmt.Sprintf("Track %d - Spotify", i)},
+			},
+			Active:  1,
+			Visible: []int64{1, 2},
+		})
+	}
+	snaps[4].Visible = []int64{1}
+	snaps[5].Visible = []int64{1}
+	snaps[5].Windows[0] = &Window{ID: 1, Name: "data.go - GoLand"}
+
+	got := CoalesceByFocus(snaps)
+	if len(got) != 3 || got[0] != snaps[0] || got[1] != snaps[4] || got[2] != snaps[5] {
+		t.Errorf("CoalesceByFocus kept %d snapshots, want snapshots 0, 4, and 5", len(got))
+	}
+}
@@ -0,0 +1,100 @@
+package thyme
+
+import "time"
+
+// Rect is a rectangle in screen coordinates.
+type Rect struct {
+	X, Y          int
+	Width, Height int
+}
+
+// contains returns true if the point (x, y) lies within the rectangle.
+func (r Rect) contains(x, y int) bool {
+	return r.X <= x && x < r.X+r.Width && r.Y <= y && y < r.Y+r.Height
+}
+
+// Output is a display output (i.e., a monitor) of the windowing system.
+type Output struct {
+	// Name is the name of the output (e.g., "HDMI-1" or "eDP-1").
+	Name string
+
+	// Rect is the area the output covers in screen coordinates.
+	Rect Rect
+
+	// Primary is true if the output is the primary output.
+	Primary bool
+}
+
+// UnknownOutput is the label given to time spent in windows whose
+// output is unknown.
+const UnknownOutput = "(unknown output)"
+
+// assignOutputs sets the Output of each window with known geometry to
+// the output containing the center of the window.
+func (s *Snapshot) assignOutputs() {
+	for _, w := range s.Windows {
+		if w.Geometry == nil {
+			continue
+		}
+		x, y := w.Geometry.X+w.Geometry.Width/2, w.Geometry.Y+w.Geometry.Height/2
+		for _, o := range s.Outputs {
+			if o.Rect.contains(x, y) {
+				w.Output = o.Name
+				break
+			}
+		}
+	}
+}
+
+// AggregateByOutput returns the total time the active window was on
+// each output. Time in windows whose output is unknown is attributed to
+// UnknownOutput.
+func AggregateByOutput(snaps []*Snapshot) map[string]time.Duration {
+	totals := make(map[string]time.Duration)
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		if snap.activeApp() == "" {
+			return
+		}
+		output := snap.ActiveWindow().Output
+		if output == "" {
+			output = UnknownOutput
+		}
+		totals[output] += end.Sub(start)
+	})
+	return totals
+}
+
+// Labels given by AggregateByPrimarySecondary to time on the primary and
+// other outputs.
+const (
+	PrimaryOutput   = "primary"
+	SecondaryOutput = "secondary"
+)
+
+// AggregateByPrimarySecondary returns the total time the active window
+// was on the primary output (labeled PrimaryOutput) and on any other
+// output (labeled SecondaryOutput). Time in windows whose output is
+// unknown, or when no output of the snapshot is primary, is attributed
+// to UnknownOutput.
+func AggregateByPrimarySecondary(snaps []*Snapshot) map[string]time.Duration {
+	totals := make(map[string]time.Duration)
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		if snap.activeApp() == "" {
+			return
+		}
+		label := UnknownOutput
+		if output := snap.ActiveWindow().Output; output != "" {
+			for _, o := range snap.Outputs {
+				if o.Primary {
+					label = SecondaryOutput
+					if o.Name == output {
+						label = PrimaryOutput
+					}
+					break
+				}
+			}
+		}
+		totals[label] += end.Sub(start)
+	})
+	return totals
+}
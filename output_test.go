@@ -0,0 +1,68 @@
+package thyme
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testOutputRecording returns a recording with two outputs, in which an
+// editor on DP-1 is active for 20 minutes, then a browser on HDMI-1 for
+// 10 minutes, then a terminal of unknown geometry for 5 minutes.
+func testOutputRecording() []*Snapshot {
+	snaps := spans(span{"main.go - GoLand", 20}, span{"Inbox - Gmail - Google Chrome", 10}, span{"~/code - Terminal", 5})
+	for i, snap := range snaps[:35] {
+		snap.Outputs = []Output{
+			{Name: "DP-1", Primary: true, Rect: Rect{Width: 1920, Height: 1080}},
+			{Name: "HDMI-1", Rect: Rect{X: 1920, Width: 1920, Height: 1080}},
+		}
+		snap.Windows = []*Window{
+			{ID: 1, Name: "main.go - GoLand", Geometry: &Rect{X: 100, Y: 100, Width: 1600, Height: 900}},
+			{ID: 2, Name: "Inbox - Gmail - Google Chrome", Geometry: &Rect{X: 1800, Width: 1400, Height: 1080}},
+			{ID: 3, Name: "~/code - Terminal"},
+		}
+		switch {
+		case i >= 30:
+			snap.Active = 3
+		case i >= 20:
+			snap.Active = 2
+		}
+		snap.assignOutputs()
+	}
+	return snaps
+}
+
+func TestAggregateByOutput(t *testing.T) {
+	snaps := testOutputRecording()
+	var outputs []string
+	for _, w := range snaps[0].Windows {
+		outputs = append(outputs, w.Output)
+	}
+	// The browser straddles both outputs, but its center is on HDMI-1.
+	if want := []string{"DP-1", "HDMI-1", ""}; !reflect.DeepEqual(outputs, want) {
+		t.Errorf("windows were assigned outputs %q, want %q", outputs, want)
+	}
+	want := map[string]time.Duration{"DP-1": 20 * time.Minute, "HDMI-1": 10 * time.Minute, UnknownOutput: 5 * time.Minute}
+	if got := AggregateByOutput(snaps); !reflect.DeepEqual(got, want) {
+		t.Errorf("AggregateByOutput = %v, want %v", got, want)
+	}
+}
+
+func TestAggregateByPrimarySecondary(t *testing.T) {
+	snaps := testOutputRecording()
+	want := map[string]time.Duration{PrimaryOutput: 20 * time.Minute, SecondaryOutput: 10 * time.Minute, UnknownOutput: 5 * time.Minute}
+	if got := AggregateByPrimarySecondary(snaps); !reflect.DeepEqual(got, want) {
+		t.Errorf("AggregateByPrimarySecondary = %v, want %v", got, want)
+	}
+
+	// Without a primary output, the monitor can't be told.
+	for _, snap := range snaps {
+		for i := range snap.Outputs {
+			snap.Outputs[i].Primary = false
+		}
+	}
+	want = map[string]time.Duration{UnknownOutput: 35 * time.Minute}
+	if got := AggregateByPrimarySecondary(snaps); !reflect.DeepEqual(got, want) {
+		t.Errorf("without a primary output, AggregateByPrimarySecondary = %v, want %v", got, want)
+	}
+}
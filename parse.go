@@ -0,0 +1,581 @@
+package thyme
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	defaultWindowTitleSeparator       = " - "
+	microsoftEdgeWindowTitleSeparator = "\u200e- "
+	colonWindowTitleSeparator         = ": "
+	emDashWindowTitleSeparator        = " \u2014 "
+	enDashWindowTitleSeparator        = " \u2013 "
+	pipeWindowTitleSeparator          = " | "
+)
+
+// ParseColonSeparator enables parsing window names of the form
+// "App: content" (used by some older GTK applications and games) when
+// the name contains no other separator. It is off by default because
+// plenty of window names contain a colon that doesn't follow an
+// application name.
+var ParseColonSeparator = false
+
+// ParsePipeSeparator enables parsing window names of the form
+// "Title | App" (used by many web applications) when the name contains
+// no " - " separator. Unlike the colon separator, it is on by default,
+// since a spaced pipe rarely appears in titles otherwise.
+var ParsePipeSeparator = true
+
+// CollapseIncognito groups all incognito Google Chrome windows under the
+// single application ChromeIncognito, with their titles redacted.
+var CollapseIncognito = false
+
+// StripIndexSuffix removes a trailing ":N" from window titles, which
+// some applications add to tell apart several views of the same
+// document (e.g., "Report.docx:2"). It is off by default because it
+// would also clobber titles that legitimately end in a number.
+var StripIndexSuffix = false
+
+// StripIDEState removes leading bracketed states (e.g., "[Debugging]" or
+// "[Building]") from the titles of development applications, which would
+// otherwise split the same file into a window per state. It is off by
+// default because some editors also use brackets for project names.
+var StripIDEState = false
+
+// StripClockTokens removes time-like tokens (e.g., "00:12:34" or
+// "9:30") from window titles, which applications showing a running timer
+// or clock change every second. It is off by default because it would
+// also remove times that identify the content (e.g., of a calendar
+// event).
+var StripClockTokens = false
+
+// NormalizeDashes makes the title parsers treat en and em dashes
+// surrounded by spaces like the default " - " separator, so that the
+// application-specific parsers recognize window names regardless of
+// which dash the application used. It is off by default because a
+// spaced dash inside a title would then be mistaken for a separator.
+var NormalizeDashes = false
+
+// ChromeIncognito is the application of incognito Google Chrome windows.
+const ChromeIncognito = "Google Chrome (Incognito)"
+
+// TitleParser extracts structured metadata from a window name. Parse
+// returns nil if it doesn't recognize the format of the window name.
+// Description, if set, describes the window names it recognizes (see
+// ClassifyExplain).
+type TitleParser struct {
+	Name        string
+	Parse       func(w *Window) *Winfo
+	Description string
+}
+
+var (
+	// specialTitleParsers handle the window names of specific
+	// applications. They are tried before genericTitleParsers, so that,
+	// e.g., a browser window name is never mistaken for a generic
+	// "content - App" name.
+	specialTitleParsers = []TitleParser{
+		{Name: "chrome-incognito", Parse: parseChromeIncognito, Description: "Google Chrome incognito window (CollapseIncognito)"},
+		{Name: "chrome", Parse: parseChrome, Description: `Google Chrome special case: "Title - SubApp - Google Chrome"`},
+		{Name: "edge", Parse: parseEdge, Description: "Microsoft Edge special case: separator prefixed with a left-to-right mark"},
+		{Name: "firefox", Parse: parseFirefox, Description: `Firefox special case: "Title - Mozilla Firefox" (with a hyphen or em dash)`},
+		{Name: "file-manager", Parse: parseFileManager, Description: "file manager window (by class) named after its folder"},
+		{Name: "remote-desktop", Parse: parseRemoteDesktop, Description: "remote desktop or VNC session named after its host"},
+		{Name: "app-both-ends", Parse: parseAppBothEnds, Description: `application name at both ends: "App - Title - App"`},
+		{Name: "app-first", Parse: parseAppFirst, Description: `application name first: "App - Title"`},
+		{Name: "vscode", Parse: parseVSCode, Description: `Visual Studio Code family: "File - Workspace - Product"`},
+		{Name: "vim", Parse: parseVim, Description: `Vim terminal title: "file (directory) - VIM"`},
+		{Name: "separated-app-name", Parse: parseSeparatedAppName, Description: `application name containing the separator: "Title - App"`},
+	}
+
+	// genericTitleParsers handle window names based only on the
+	// separators they contain.
+	genericTitleParsers = []TitleParser{
+		{Name: "app-last", Parse: parseAppLast, Description: `" - " separator with the application name last: "Title - App"`},
+		{Name: "windows-folder", Parse: parseWindowsFolder, Description: "Windows Explorer window named after its folder path"},
+		{Name: "pipe", Parse: parsePipe, Description: `" | " separator with the application name last: "Title | App"`},
+		{Name: "colon", Parse: parseColon, Description: `": " separator with the application name first (ParseColonSeparator)`},
+	}
+)
+
+// RegisterTitleParser adds p to the parsers used by Window.Info. It is
+// tried after the built-in application-specific parsers and before the
+// generic separator-based ones.
+func RegisterTitleParser(p TitleParser) {
+	specialTitleParsers = append(specialTitleParsers, p)
+}
+
+// TitleParsers returns the names of the parsers used by Window.Info in
+// the order they are tried.
+func TitleParsers() []string {
+	var names []string
+	for _, p := range specialTitleParsers {
+		names = append(names, p.Name)
+	}
+	for _, p := range genericTitleParsers {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// parse returns the metadata of the window along with the name of the
+// parser that produced it, or "" if no parser recognized the window
+// name.
+func (w *Window) parse() (*Winfo, string) {
+	if NormalizeDashes {
+		normalized := *w
+		normalized.Name = dashReplacer.Replace(w.Name)
+		w = &normalized
+	}
+	for _, parsers := range [][]TitleParser{specialTitleParsers, genericTitleParsers} {
+		for _, p := range parsers {
+			if info := p.Parse(w); info != nil {
+				normalize(info)
+				info.Project = windowProject(w.Name)
+				return info, p.Name
+			}
+		}
+	}
+
+	// No Application name separator. Applications with client-side
+	// decorations (e.g., GTK header bars) often show only the document
+	// name, so fall back to the window class, if known.
+	info := &Winfo{
+		App:   w.Class,
+		Title: w.Name,
+	}
+	normalize(info)
+	info.Project = windowProject(w.Name)
+	return info, ""
+}
+
+// normalize removes volatile parts of parsed window metadata that would
+// otherwise split the same window into many.
+func normalize(info *Winfo) {
+	if info.IsBrowser() {
+		// Some extensions prepend an unread or tab count.
+		info.SubApp = tabCountRx.ReplaceAllString(info.SubApp, "")
+		info.Title = tabCountRx.ReplaceAllString(info.Title, "")
+		normalizeJupyter(info)
+	}
+	if StripIndexSuffix {
+		info.Title = indexSuffixRx.ReplaceAllString(info.Title, "")
+	}
+	if StripClockTokens {
+		info.Title = strings.Join(strings.Fields(clockTokenRx.ReplaceAllString(info.Title, "")), " ")
+	}
+	if StripIDEState && info.Category() == DevelopmentCategory {
+		info.Title = ideStateRx.ReplaceAllString(info.Title, "")
+	}
+}
+
+// JupyterSubApp is the SubApp of browser windows showing a Jupyter
+// notebook, whether in the classic Notebook interface or JupyterLab.
+const JupyterSubApp = "Jupyter"
+
+// jupyterNames are the names Jupyter interfaces append to their page
+// titles.
+var jupyterNames = []string{"Jupyter Notebook", "JupyterLab"}
+
+// normalizeJupyter recognizes browser windows showing a Jupyter
+// notebook, whose page title has the form "notebook - Jupyter Notebook"
+// or "notebook.ipynb - JupyterLab" (possibly with an em dash), and
+// attributes them to JupyterSubApp with the notebook name as Title.
+func normalizeJupyter(info *Winfo) {
+	for _, name := range jupyterNames {
+		if info.SubApp == name {
+			info.SubApp = JupyterSubApp
+			return
+		}
+		if info.SubApp != "" {
+			continue
+		}
+		for _, sep := range []string{defaultWindowTitleSeparator, emDashWindowTitleSeparator} {
+			if notebook := strings.TrimSuffix(info.Title, sep+name); notebook != info.Title {
+				info.SubApp = JupyterSubApp
+				info.Title = strings.TrimSpace(notebook)
+				return
+			}
+		}
+	}
+}
+
+// dashReplacer replaces spaced en and em dashes with the default
+// separator.
+var dashReplacer = strings.NewReplacer(
+	enDashWindowTitleSeparator, defaultWindowTitleSeparator,
+	emDashWindowTitleSeparator, defaultWindowTitleSeparator,
+)
+
+var (
+	tabCountRx    = regexp.MustCompile(`^\([0-9]+\) `)
+	indexSuffixRx = regexp.MustCompile(`:[0-9]+$`)
+	ideStateRx    = regexp.MustCompile(`^\[[^\]]+\]\s*`)
+	clockTokenRx  = regexp.MustCompile(`\b[0-9]{1,2}:[0-9]{2}(?::[0-9]{2})?\b`)
+)
+
+// parseChrome parses Google Chrome window names, which have the form
+// "Title - SubApp - Google Chrome", optionally followed by " - Profile".
+func parseChrome(w *Window) *Winfo {
+	fields := strings.Split(w.Name, defaultWindowTitleSeparator)
+	for i := len(fields) - 1; i >= 1 && i >= len(fields)-2; i-- {
+		if strings.TrimSpace(fields[i]) == "Google Chrome" {
+			info := &Winfo{
+				App:    "Google Chrome",
+				SubApp: strings.TrimSpace(fields[i-1]),
+				Title:  strings.Join(fields[0:i-1], defaultWindowTitleSeparator),
+			}
+			if sep := strings.LastIndex(info.SubApp, pipeWindowTitleSeparator); ParsePipeSeparator && info.Title == "" && sep > -1 {
+				// "Title | SubApp - Google Chrome"
+				info.Title = strings.TrimSpace(info.SubApp[:sep])
+				info.SubApp = strings.TrimSpace(info.SubApp[sep+len(pipeWindowTitleSeparator):])
+			}
+			return info
+		}
+	}
+	return nil
+}
+
+// parseChromeIncognito parses incognito Google Chrome window names,
+// which end in " - Google Chrome (Incognito)", if CollapseIncognito is
+// enabled.
+func parseChromeIncognito(w *Window) *Winfo {
+	if !CollapseIncognito {
+		return nil
+	}
+	if w.Name != ChromeIncognito && !strings.HasSuffix(w.Name, defaultWindowTitleSeparator+ChromeIncognito) {
+		return nil
+	}
+	return &Winfo{
+		App:   ChromeIncognito,
+		Title: RedactedTitle,
+	}
+}
+
+// parseEdge parses Microsoft Edge window names, which use a separator
+// prefixed with a left-to-right mark.
+func parseEdge(w *Window) *Winfo {
+	if !strings.Contains(w.Name, microsoftEdgeWindowTitleSeparator) {
+		return nil
+	}
+	// App Name Last
+	beforeSep := strings.LastIndex(w.Name, microsoftEdgeWindowTitleSeparator)
+	afterSep := beforeSep + len(microsoftEdgeWindowTitleSeparator)
+	return &Winfo{
+		App:   strings.TrimSpace(w.Name[afterSep:]),
+		Title: strings.TrimSpace(w.Name[:beforeSep]),
+	}
+}
+
+// firefoxApps are the application names Firefox appends to its window
+// names, longest first.
+var firefoxApps = []string{"Mozilla Firefox Private Browsing", "Mozilla Firefox"}
+
+// parseFirefox parses Firefox window names, which have the form
+// "Title - Mozilla Firefox" using either a hyphen or, in recent
+// versions, an em dash, regardless of NormalizeDashes.
+func parseFirefox(w *Window) *Winfo {
+	for _, app := range firefoxApps {
+		for _, sep := range []string{emDashWindowTitleSeparator, defaultWindowTitleSeparator} {
+			if title := strings.TrimSuffix(w.Name, sep+app); title != w.Name {
+				return &Winfo{App: "Mozilla Firefox", Title: strings.TrimSpace(title)}
+			}
+		}
+	}
+	return nil
+}
+
+// fileManagerClasses maps the window classes of file managers to their
+// application name.
+var fileManagerClasses = map[string]string{
+	"org.gnome.Nautilus": "Files",
+	"Nautilus":           "Files",
+	"Thunar":             "Thunar",
+	"dolphin":            "Dolphin",
+	"Nemo":               "Nemo",
+	"CabinetWClass":      "Explorer",
+}
+
+// parseFileManager parses the window names of file managers, which are
+// the path or name of the current folder. Folder paths are shortened to
+// their last segment, keeping the full path in Raw.
+func parseFileManager(w *Window) *Winfo {
+	app, ok := fileManagerClasses[w.Class]
+	if !ok {
+		return nil
+	}
+	return folderInfo(app, w.Name)
+}
+
+// folderInfo returns the metadata of the window of the file manager app
+// showing the folder path.
+func folderInfo(app, path string) *Winfo {
+	info := &Winfo{App: app, Title: path}
+	if i := strings.LastIndexAny(strings.TrimRight(path, `/\`), `/\`); i > -1 {
+		if folder := strings.TrimRight(path[i+1:], `/\`); folder != "" {
+			info.Title = folder
+			info.Raw = path
+		}
+	}
+	return info
+}
+
+// windowsFolderRx matches Windows folder paths (i.e., paths whose last
+// segment has no extension), which Windows Explorer uses as window
+// names.
+var windowsFolderRx = regexp.MustCompile(`^[A-Za-z]:\\(?:[^\\]+\\)*[^\\.]*\\?$`)
+
+// parseWindowsFolder parses window names that are nothing but a Windows
+// folder path as Windows Explorer windows, for trackers that don't
+// record window classes. It is tried only after the separator-based
+// parsers, so that, e.g., "C:\proj\main.go - Visual Studio Code" is
+// left to them.
+func parseWindowsFolder(w *Window) *Winfo {
+	if !windowsFolderRx.MatchString(w.Name) {
+		return nil
+	}
+	return folderInfo("Explorer", w.Name)
+}
+
+// Applications of remote sessions, as parsed by parseRemoteDesktop.
+const (
+	RemoteDesktopApp = "Remote Desktop"
+	VNCApp           = "VNC"
+)
+
+// remoteDesktopSuffixes maps the suffixes of the window names of remote
+// desktop clients, which have the form "host - Client", to the
+// application of the remote session.
+var remoteDesktopSuffixes = map[string]string{
+	" - Remote Desktop Connection": RemoteDesktopApp,
+	" - Remote Desktop":            RemoteDesktopApp,
+	" - VNC Viewer":                VNCApp,
+}
+
+// vncTitleRx matches the "host:display (TigerVNC)" window names of VNC
+// viewers, where host may be prefixed with "user@".
+var vncTitleRx = regexp.MustCompile(`^(?:[^@ ]+@)?(\S+):[0-9]+ \([^)]*VNC\)$`)
+
+// parseRemoteDesktop parses the window names of remote desktop (RDP) and
+// VNC clients, attributing the window to the remote session with the
+// remote host as SubApp.
+func parseRemoteDesktop(w *Window) *Winfo {
+	for suffix, app := range remoteDesktopSuffixes {
+		if host := strings.TrimSuffix(w.Name, suffix); host != w.Name && host != "" {
+			return &Winfo{App: app, SubApp: strings.TrimSpace(host)}
+		}
+	}
+	if m := vncTitleRx.FindStringSubmatch(w.Name); m != nil {
+		return &Winfo{App: VNCApp, SubApp: m[1]}
+	}
+	return nil
+}
+
+// parseAppBothEnds parses window names that have the application name at
+// both ends, as in "App - Title - App" (using a hyphen, en dash, or em
+// dash), so that the leading application name isn't left in the title.
+func parseAppBothEnds(w *Window) *Winfo {
+	for _, sep := range []string{defaultWindowTitleSeparator, enDashWindowTitleSeparator, emDashWindowTitleSeparator} {
+		first, last := strings.Index(w.Name, sep), strings.LastIndex(w.Name, sep)
+		if first == -1 || first == last {
+			continue
+		}
+		if app := w.Name[:first]; app != "" && app == w.Name[last+len(sep):] {
+			return &Winfo{
+				App:   strings.TrimSpace(app),
+				Title: strings.TrimSpace(w.Name[first+len(sep) : last]),
+			}
+		}
+	}
+	return nil
+}
+
+// appFirstApps is a set of the few programs that put their name first in
+// their window names.
+var appFirstApps = map[string]struct{}{
+	"Slack": {},
+}
+
+// RegisterAppFirst registers the name of an application whose window
+// names have the form "App - Title", so that they aren't parsed as
+// "Title - App".
+func RegisterAppFirst(app string) {
+	appFirstApps[app] = struct{}{}
+}
+
+// parseAppFirst parses the window names of the applications in
+// appFirstApps, whose leading segment is the application name.
+func parseAppFirst(w *Window) *Winfo {
+	beforeSep := strings.Index(w.Name, defaultWindowTitleSeparator)
+	if beforeSep == -1 {
+		return nil
+	}
+	app := strings.TrimSpace(w.Name[:beforeSep])
+	if _, ok := appFirstApps[app]; !ok {
+		return nil
+	}
+	afterSep := beforeSep + len(defaultWindowTitleSeparator)
+	return &Winfo{
+		App:   app,
+		Title: strings.TrimSpace(w.Name[afterSep:]),
+	}
+}
+
+// separatedAppNames are application names that contain the default
+// separator themselves.
+var separatedAppNames = []string{
+	"Visual Studio Code - Insiders",
+	"Code - OSS",
+	"Code - Insiders",
+}
+
+// RegisterSeparatedAppName registers the name of an application that
+// contains the " - " separator (e.g., "Visual Studio Code - Insiders"),
+// so that window names ending in it aren't split inside the application
+// name.
+func RegisterSeparatedAppName(name string) {
+	separatedAppNames = append(separatedAppNames, name)
+}
+
+// parseSeparatedAppName parses "Title - App" window names whose App is
+// one of separatedAppNames. The longest matching application name wins.
+func parseSeparatedAppName(w *Window) *Winfo {
+	var app string
+	for _, name := range separatedAppNames {
+		if len(name) > len(app) && (w.Name == name || strings.HasSuffix(w.Name, defaultWindowTitleSeparator+name)) {
+			app = name
+		}
+	}
+	if app == "" {
+		return nil
+	}
+	title := strings.TrimSuffix(strings.TrimSuffix(w.Name, app), defaultWindowTitleSeparator)
+	return &Winfo{
+		App:   app,
+		Title: strings.TrimSpace(title),
+	}
+}
+
+// vsCodeFamily are the names of Visual Studio Code and the editors
+// derived from it, which all name their windows
+// "File - Workspace - Product".
+var vsCodeFamily = []string{
+	"Visual Studio Code",
+	"Visual Studio Code - Insiders",
+	"Code - OSS",
+	"VSCodium",
+	"Cursor",
+	"Positron",
+}
+
+// RegisterVSCodeFamily registers the product name of an editor derived
+// from Visual Studio Code, so that its windows are parsed like Visual
+// Studio Code's.
+func RegisterVSCodeFamily(name string) {
+	vsCodeFamily = append(vsCodeFamily, name)
+}
+
+// parseVSCode parses the window names of the Visual Studio Code family
+// of editors, which have the form "File - Workspace - Product" (using
+// either a hyphen or an em dash), possibly prefixed with a dot marking
+// unsaved changes. The longest matching product name wins.
+func parseVSCode(w *Window) *Winfo {
+	var app, rest, sep string
+	for _, s := range []string{defaultWindowTitleSeparator, emDashWindowTitleSeparator} {
+		for _, name := range vsCodeFamily {
+			if len(name) > len(app) && strings.HasSuffix(w.Name, s+name) {
+				app, rest, sep = name, strings.TrimSuffix(w.Name, s+name), s
+			}
+		}
+	}
+	if app == "" {
+		return nil
+	}
+	rest = strings.TrimPrefix(rest, "\u25cf ")
+
+	info := &Winfo{App: app}
+	if i := strings.LastIndex(rest, sep); i > -1 {
+		info.SubApp = strings.TrimSpace(rest[i+len(sep):])
+		info.Title = strings.TrimSpace(rest[:i])
+	} else {
+		info.Title = strings.TrimSpace(rest)
+	}
+	return info
+}
+
+// vimApps maps the suffixes Vim and Neovim add to terminal titles to the
+// application name.
+var vimApps = map[string]string{
+	" - NVIM": "Neovim",
+	" - VIM":  "Vim",
+}
+
+// vimTitleRx matches the default Vim title "file [+] (directory)", where
+// "+" marks unsaved changes.
+var vimTitleRx = regexp.MustCompile(`^(.*?)(?: [-+=]+)? \((.*)\)$`)
+
+// parseVim parses the terminal titles set by Vim and Neovim, which have
+// the form "file (directory) - VIM", using the directory as SubApp.
+func parseVim(w *Window) *Winfo {
+	for suffix, app := range vimApps {
+		if !strings.HasSuffix(w.Name, suffix) {
+			continue
+		}
+		rest := strings.TrimSuffix(w.Name, suffix)
+		if m := vimTitleRx.FindStringSubmatch(rest); m != nil {
+			return &Winfo{App: app, SubApp: m[2], Title: m[1]}
+		}
+		return &Winfo{App: app, Title: strings.TrimSpace(rest)}
+	}
+	return nil
+}
+
+// parseAppLast parses window names of the common "Title - App" form.
+func parseAppLast(w *Window) *Winfo {
+	beforeSep := strings.LastIndex(w.Name, defaultWindowTitleSeparator)
+	if beforeSep == -1 {
+		return nil
+	}
+	afterSep := beforeSep + len(defaultWindowTitleSeparator)
+	return &Winfo{
+		App:   strings.TrimSpace(w.Name[afterSep:]),
+		Title: strings.TrimSpace(w.Name[:beforeSep]),
+	}
+}
+
+// parsePipe parses window names of the form "Title | App" if
+// ParsePipeSeparator is enabled.
+func parsePipe(w *Window) *Winfo {
+	if !ParsePipeSeparator {
+		return nil
+	}
+	beforeSep := strings.LastIndex(w.Name, pipeWindowTitleSeparator)
+	if beforeSep == -1 {
+		return nil
+	}
+	// App Name Last
+	afterSep := beforeSep + len(pipeWindowTitleSeparator)
+	return &Winfo{
+		App:   strings.TrimSpace(w.Name[afterSep:]),
+		Title: strings.TrimSpace(w.Name[:beforeSep]),
+	}
+}
+
+// parseColon parses window names of the form "App: Title" if
+// ParseColonSeparator is enabled.
+func parseColon(w *Window) *Winfo {
+	if !ParseColonSeparator {
+		return nil
+	}
+	beforeSep := strings.Index(w.Name, colonWindowTitleSeparator)
+	if beforeSep <= 0 {
+		return nil
+	}
+	// App Name First
+	afterSep := beforeSep + len(colonWindowTitleSeparator)
+	return &Winfo{
+		App:   strings.TrimSpace(w.Name[:beforeSep]),
+		Title: strings.TrimSpace(w.Name[afterSep:]),
+	}
+}
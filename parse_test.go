@@ -0,0 +1,231 @@
+package thyme
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// infoTest is a window name (and class) along with its expected
+// metadata.
+type infoTest struct {
+	name, class string
+	want        Winfo
+}
+
+func checkInfo(t *testing.T, tests []infoTest) {
+	t.Helper()
+	for _, test := range tests {
+		w := &Window{Name: test.name, Class: test.class}
+		if got := *w.Info(); got != test.want {
+			t.Errorf("Info of %q (class %q) = %+v, want %+v", test.name, test.class, got, test.want)
+		}
+	}
+}
+
+func TestParseChrome(t *testing.T) {
+	checkInfo(t, []infoTest{
+		{name: "Inbox - Gmail - Google Chrome", want: Winfo{App: "Google Chrome", SubApp: "Gmail", Title: "Inbox"}},
+		// Tab counts prepended by extensions are stripped.
+		{name: "(3) Inbox - Gmail - Google Chrome", want: Winfo{App: "Google Chrome", SubApp: "Gmail", Title: "Inbox"}},
+		{name: "(7) Inbox - Gmail - Google Chrome", want: Winfo{App: "Google Chrome", SubApp: "Gmail", Title: "Inbox"}},
+		{name: "Dashboard | Grafana - Google Chrome", want: Winfo{App: "Google Chrome", SubApp: "Grafana", Title: "Dashboard"}},
+	})
+}
+
+// TestParserOrder checks that application-specific parsers are tried
+// before generic ones, so that an ambiguous title is parsed by the
+// former.
+func TestParserOrder(t *testing.T) {
+	want := []string{
+		"chrome-incognito", "chrome", "edge", "firefox", "file-manager", "remote-desktop",
+		"app-both-ends", "app-first", "vscode", "vim", "separated-app-name",
+		"app-last", "windows-folder", "pipe", "colon",
+	}
+	if got := TitleParsers(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TitleParsers = %q, want %q", got, want)
+	}
+
+	// Both the Chrome parser and the generic app-last parser could
+	// parse this title.
+	w := &Window{Name: "Project - Documentation - Google Chrome - Profile"}
+	info, parser := w.parse()
+	if want := (Winfo{App: "Google Chrome", SubApp: "Documentation", Title: "Project"}); parser != "chrome" || *info != want {
+		t.Errorf("%q parsed by %q as %+v, want by \"chrome\" as %+v", w.Name, parser, *info, want)
+	}
+}
+
+func TestParseFirefox(t *testing.T) {
+	checkInfo(t, []infoTest{
+		{name: "Page - Mozilla Firefox", want: Winfo{App: "Mozilla Firefox", Title: "Page"}},
+		// Firefox uses an em dash, whether or not NormalizeDashes is set.
+		{name: "Page — Mozilla Firefox", want: Winfo{App: "Mozilla Firefox", Title: "Page"}},
+		{name: "Page — Mozilla Firefox Private Browsing", want: Winfo{App: "Mozilla Firefox", Title: "Page"}},
+	})
+}
+
+func TestParseFileManager(t *testing.T) {
+	checkInfo(t, []infoTest{
+		{name: "/home/me/code", class: "org.gnome.Nautilus", want: Winfo{App: "Files", Title: "code", Raw: "/home/me/code"}},
+		{name: `C:\Users\me\Documents`, class: "CabinetWClass", want: Winfo{App: "Explorer", Title: "Documents", Raw: `C:\Users\me\Documents`}},
+		{name: "Downloads", class: "Thunar", want: Winfo{App: "Thunar", Title: "Downloads"}},
+	})
+}
+
+func TestParseWindowsFolder(t *testing.T) {
+	checkInfo(t, []infoTest{
+		{name: `C:\Users\me\Documents`, want: Winfo{App: "Explorer", Title: "Documents", Raw: `C:\Users\me\Documents`}},
+		// A path in the title of another application doesn't make it
+		// a file manager.
+		{name: `notes - C:\Users\me - Notepad++`, want: Winfo{App: "Notepad++", Title: `notes - C:\Users\me`}},
+		{name: `C:\Users\me\notes.txt`, want: Winfo{Title: `C:\Users\me\notes.txt`}},
+	})
+}
+
+func TestParseAppBothEnds(t *testing.T) {
+	checkInfo(t, []infoTest{
+		{name: "GIMP — image.xcf — GIMP", want: Winfo{App: "GIMP", Title: "image.xcf"}},
+		{name: "GIMP - image.xcf - GIMP", want: Winfo{App: "GIMP", Title: "image.xcf"}},
+	})
+}
+
+func TestParseVSCode(t *testing.T) {
+	checkInfo(t, []infoTest{
+		{name: "main.go - thyme - Visual Studio Code", want: Winfo{App: "Visual Studio Code", SubApp: "thyme", Title: "main.go"}},
+		{name: "main.go - thyme - Cursor", want: Winfo{App: "Cursor", SubApp: "thyme", Title: "main.go"}},
+		{name: "main.go - thyme - VSCodium", want: Winfo{App: "VSCodium", SubApp: "thyme", Title: "main.go"}},
+		{name: "file.go - project - Visual Studio Code - Insiders", want: Winfo{App: "Visual Studio Code - Insiders", SubApp: "project", Title: "file.go"}},
+	})
+}
+
+func TestParseVim(t *testing.T) {
+	checkInfo(t, []infoTest{
+		{name: "main.go (~/app) - NVIM", want: Winfo{App: "Neovim", SubApp: "~/app", Title: "main.go"}},
+		{name: "main.go (~/app) - VIM", want: Winfo{App: "Vim", SubApp: "~/app", Title: "main.go"}},
+	})
+}
+
+func TestParseSeparatedAppName(t *testing.T) {
+	checkInfo(t, []infoTest{
+		{name: "build.log - Code - OSS", want: Winfo{App: "Code - OSS", Title: "build.log"}},
+	})
+}
+
+func TestParsePipe(t *testing.T) {
+	checkInfo(t, []infoTest{
+		{name: "Issue #5 | GitLab", want: Winfo{App: "GitLab", Title: "Issue #5"}},
+		{name: "Dashboard | Grafana", want: Winfo{App: "Grafana", Title: "Dashboard"}},
+	})
+}
+
+// TestParseOptions checks that the opt-in normalizations make window
+// names that only differ by a volatile part parse the same, and that
+// they don't without the option.
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		option *bool
+		a, b   string
+	}{
+		{&StripIndexSuffix, "Report.docx:2 - Word", "Report.docx:1 - Word"},
+		{&StripIDEState, "[Debugging] main.go - GoLand", "main.go - GoLand"},
+		{&StripClockTokens, "Recording 00:12:34 - OBS", "Recording 00:12:35 - OBS"},
+		{&NormalizeDashes, "Inbox - Gmail — Google Chrome", "Inbox - Gmail - Google Chrome"},
+	}
+	for _, test := range tests {
+		a, b := &Window{Name: test.a}, &Window{Name: test.b}
+		if *a.Info() == *b.Info() {
+			t.Errorf("%q and %q parse the same without the option: %+v", test.a, test.b, *a.Info())
+		}
+		*test.option = true
+		if *a.Info() != *b.Info() {
+			t.Errorf("%q and %q parse differently with the option: %+v and %+v", test.a, test.b, *a.Info(), *b.Info())
+		}
+		*test.option = false
+	}
+}
+
+func TestParseColon(t *testing.T) {
+	checkInfo(t, []infoTest{
+		{name: "Inkscape: drawing.svg", want: Winfo{Title: "Inkscape: drawing.svg"}},
+	})
+	ParseColonSeparator = true
+	defer func() { ParseColonSeparator = false }()
+	checkInfo(t, []infoTest{
+		{name: "Inkscape: drawing.svg", want: Winfo{App: "Inkscape", Title: "drawing.svg"}},
+		// Other separators take precedence.
+		{name: "Meeting at 12:34: notes - GoLand", want: Winfo{App: "GoLand", Title: "Meeting at 12:34: notes"}},
+		{name: "Recording 12:34", want: Winfo{Title: "Recording 12:34"}},
+	})
+}
+
+func TestCollapseIncognito(t *testing.T) {
+	const incognito = "Bank statement - Google Chrome (Incognito)"
+	snaps := spans(span{incognito, 10}, span{"Inbox - Gmail - Google Chrome", 20})
+	// Without the option, the title of the page isn't redacted.
+	if info := (&Window{Name: incognito}).Info(); info.Title == RedactedTitle {
+		t.Errorf("without CollapseIncognito, Info of %q = %+v, want the title kept", incognito, *info)
+	}
+
+	CollapseIncognito = true
+	defer func() { CollapseIncognito = false }()
+	checkInfo(t, []infoTest{
+		{name: incognito, want: Winfo{App: ChromeIncognito, Title: RedactedTitle}},
+		{name: ChromeIncognito, want: Winfo{App: ChromeIncognito, Title: RedactedTitle}},
+	})
+	want := map[string]time.Duration{ChromeIncognito: 10 * time.Minute, "Google Chrome": 20 * time.Minute}
+	if got := AggregateByApp(snaps); !reflect.DeepEqual(got, want) {
+		t.Errorf("with CollapseIncognito, AggregateByApp = %v, want %v", got, want)
+	}
+}
+
+func TestParseClassFallback(t *testing.T) {
+	checkInfo(t, []infoTest{
+		{name: "notes.txt", class: "org.gnome.TextEditor", want: Winfo{App: "org.gnome.TextEditor", Title: "notes.txt"}},
+		// The window name takes precedence when it has a separator.
+		{name: "notes.txt - gedit", class: "org.gnome.TextEditor", want: Winfo{App: "gedit", Title: "notes.txt"}},
+		{name: "notes.txt", want: Winfo{Title: "notes.txt"}},
+	})
+}
+
+func TestParseRemoteDesktop(t *testing.T) {
+	checkInfo(t, []infoTest{
+		{name: "prod-server - Remote Desktop Connection", want: Winfo{App: RemoteDesktopApp, SubApp: "prod-server"}},
+		{name: "prod-server - Remote Desktop", want: Winfo{App: RemoteDesktopApp, SubApp: "prod-server"}},
+		{name: "build-box - VNC Viewer", want: Winfo{App: VNCApp, SubApp: "build-box"}},
+		{name: "me@build-box:1 (TigerVNC)", want: Winfo{App: VNCApp, SubApp: "build-box"}},
+	})
+}
+
+func TestParseAppFirst(t *testing.T) {
+	tests := []struct {
+		name, parser string
+		want         Winfo
+	}{
+		{"Slack - message", "app-first", Winfo{App: "Slack", Title: "message"}},
+		{"Notes - message", "app-last", Winfo{App: "message", Title: "Notes"}},
+		{"general - Acme - Slack", "app-last", Winfo{App: "Slack", Title: "general - Acme"}},
+	}
+	for _, test := range tests {
+		w := &Window{Name: test.name}
+		if info, parser := w.parse(); parser != test.parser || *info != test.want {
+			t.Errorf("%q parsed by %q as %+v, want by %q as %+v", test.name, parser, *info, test.parser, test.want)
+		}
+	}
+
+	RegisterAppFirst("Notes")
+	defer delete(appFirstApps, "Notes")
+	w := &Window{Name: "Notes - message"}
+	if info, parser := w.parse(); parser != "app-first" || *info != (Winfo{App: "Notes", Title: "message"}) {
+		t.Errorf("after registering Notes, %q parsed by %q as %+v", w.Name, parser, *info)
+	}
+}
+
+func TestParseJupyter(t *testing.T) {
+	checkInfo(t, []infoTest{
+		{name: "analysis.ipynb - JupyterLab - Google Chrome", want: Winfo{App: "Google Chrome", SubApp: "Jupyter", Title: "analysis.ipynb"}},
+		{name: "analysis - Jupyter Notebook — Mozilla Firefox", want: Winfo{App: "Mozilla Firefox", SubApp: "Jupyter", Title: "analysis"}},
+		{name: "analysis.ipynb — JupyterLab — Mozilla Firefox", want: Winfo{App: "Mozilla Firefox", SubApp: "Jupyter", Title: "analysis.ipynb"}},
+		// Outside a browser, the title is left alone.
+		{name: "analysis.ipynb - JupyterLab", want: Winfo{App: "JupyterLab", Title: "analysis.ipynb"}},
+	})
+}
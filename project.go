@@ -0,0 +1,50 @@
+package thyme
+
+import (
+	"regexp"
+	"time"
+)
+
+// projectPattern maps window names matching a pattern to a project.
+type projectPattern struct {
+	re      *regexp.Regexp
+	project string
+}
+
+// projectPatterns are the patterns registered with
+// RegisterProjectPattern, in order.
+var projectPatterns []projectPattern
+
+// RegisterProjectPattern makes windows whose name matches re (e.g., a
+// path containing "code/thyme/") belong to project. Patterns are tried
+// in the order they were registered, and the first to match wins.
+func RegisterProjectPattern(re *regexp.Regexp, project string) {
+	projectPatterns = append(projectPatterns, projectPattern{re: re, project: project})
+}
+
+// windowProject returns the project of the window named name, or "" if
+// no project pattern matches it.
+func windowProject(name string) string {
+	for _, p := range projectPatterns {
+		if p.re.MatchString(name) {
+			return p.project
+		}
+	}
+	return ""
+}
+
+// NoProject is the label given by AggregateByProject to time spent in
+// windows that don't belong to a project.
+const NoProject = "(no project)"
+
+// AggregateByProject returns the total time spent in the windows of each
+// project (see RegisterProjectPattern). Time in windows that don't
+// belong to a project is labeled NoProject.
+func AggregateByProject(snaps []*Snapshot) map[string]time.Duration {
+	return AggregateBy(snaps, func(w *Window) string {
+		if project := w.Info().Project; project != "" {
+			return project
+		}
+		return NoProject
+	})
+}
@@ -0,0 +1,26 @@
+package thyme
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestProject(t *testing.T) {
+	defer func(patterns []projectPattern) { projectPatterns = patterns }(projectPatterns)
+	RegisterProjectPattern(regexp.MustCompile(`\bthyme/`), "thyme")
+	RegisterProjectPattern(regexp.MustCompile(`/code/`), "other")
+
+	checkInfo(t, []infoTest{
+		{name: "~/code/thyme/data.go - Code", want: Winfo{App: "Code", Title: "~/code/thyme/data.go", Project: "thyme"}},
+		{name: "~/code/site/index.html - Code", want: Winfo{App: "Code", Title: "~/code/site/index.html", Project: "other"}},
+		{name: "Inbox - Gmail - Google Chrome", want: Winfo{App: "Google Chrome", SubApp: "Gmail", Title: "Inbox"}},
+	})
+
+	snaps := spans(span{"~/code/thyme/data.go - Code", 20}, span{"Inbox - Gmail - Google Chrome", 10})
+	want := map[string]time.Duration{"thyme": 20 * time.Minute, NoProject: 10 * time.Minute}
+	if got := AggregateByProject(snaps); !reflect.DeepEqual(got, want) {
+		t.Errorf("AggregateByProject = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,46 @@
+package thyme
+
+import "strings"
+
+// RedactedTitle is the title given to windows whose title has been
+// redacted.
+const RedactedTitle = "(redacted)"
+
+// RedactBrowserTitles returns copies of snaps in which the title of
+// every browser window is replaced with RedactedTitle. The browser and
+// sub-application (i.e., the site) are preserved, so the redacted
+// snapshots can still be aggregated by site. Browser windows whose names
+// can't be parsed are recognized by their class, if known, and their
+// whole name is redacted. Non-browser windows are left untouched.
+func RedactBrowserTitles(snaps []*Snapshot) []*Snapshot {
+	redacted := make([]*Snapshot, 0, len(snaps))
+	for _, snap := range snaps {
+		c := snap.clone()
+		for _, w := range c.Windows {
+			info := w.Info()
+			if !info.IsBrowser() {
+				app, ok := browserClasses[w.Class]
+				if !ok {
+					continue
+				}
+				// The name wasn't recognized, so none of it can be kept.
+				info = &Winfo{App: app}
+			}
+			info.Title = RedactedTitle
+			w.Name = info.windowName()
+		}
+		redacted = append(redacted, c)
+	}
+	return redacted
+}
+
+// windowName returns a window name that Info parses back into w.
+func (w Winfo) windowName() string {
+	var fields []string
+	for _, f := range []string{w.Title, w.SubApp, w.App} {
+		if f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return strings.Join(fields, defaultWindowTitleSeparator)
+}
@@ -0,0 +1,25 @@
+package thyme
+
+import "testing"
+
+func TestRedactBrowserTitles(t *testing.T) {
+	tests := []struct {
+		name, class, want string
+	}{
+		{name: "Secret plans - Gmail - Google Chrome", want: "(redacted) - Gmail - Google Chrome"},
+		{name: "Secret plans — Mozilla Firefox", want: "(redacted) - Mozilla Firefox"},
+		{name: "Secret plans — Mozilla Firefox Private Browsing", want: "(redacted) - Mozilla Firefox"},
+		// Unrecognized names of browser windows are redacted whole.
+		{name: "Secret plans", class: "firefox", want: "(redacted) - Firefox"},
+		{name: "main.go - GoLand", want: "main.go - GoLand"},
+	}
+	for _, test := range tests {
+		snap := &Snapshot{Windows: []*Window{{ID: 1, Name: test.name, Class: test.class}}, Active: 1}
+		if got := RedactBrowserTitles([]*Snapshot{snap})[0].Windows[0].Name; got != test.want {
+			t.Errorf("redacted name of %q (class %q) = %q, want %q", test.name, test.class, got, test.want)
+		}
+		if snap.Windows[0].Name != test.name {
+			t.Errorf("redacting %q modified the original window", test.name)
+		}
+	}
+}
@@ -5,3 +5,8 @@ for os in darwin windows linux; do
         env GOOS="$os" GOARCH="$arch" go build -o "./.bin/thyme-$os-$arch" ./cmd/thyme
     done
 done
+
+# The analysis code (everything but the trackers) must also build and
+# work under WebAssembly.
+env GOOS=js GOARCH=wasm go build .
+env GOOS=js GOARCH=wasm go test -exec "$(go env GOROOT)/lib/wasm/go_js_wasm_exec" -run WebAssembly .
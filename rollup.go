@@ -0,0 +1,81 @@
+package thyme
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// rollupHeader is the header row of daily rollup CSV files.
+var rollupHeader = []string{"date", "app", "seconds"}
+
+// WriteDailyRollupCSV writes the total time each application was active
+// on each day in loc to w as CSV with the columns date (e.g.,
+// "2006-01-02"), app, and seconds, preceded by a header row. There is
+// one row per day and application, ordered by date and then by
+// application, so that rollups of successive recordings can be appended
+// to the same file. Use ReadDailyRollupCSV to read it.
+func WriteDailyRollupCSV(w io.Writer, snaps []*Snapshot, loc *time.Location) error {
+	totals := aggregateByPeriod(snaps, nextDay(loc), func(t time.Time) string {
+		return t.In(loc).Format(dayFormat)
+	})
+	days := make([]string, 0, len(totals))
+	for day := range totals {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(rollupHeader); err != nil {
+		return err
+	}
+	for _, day := range days {
+		apps := make([]string, 0, len(totals[day]))
+		for app := range totals[day] {
+			apps = append(apps, app)
+		}
+		sort.Strings(apps)
+		for _, app := range apps {
+			seconds := strconv.FormatFloat(totals[day][app].Seconds(), 'f', -1, 64)
+			if err := cw.Write([]string{day, app, seconds}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadDailyRollupCSV reads a daily rollup written by WriteDailyRollupCSV
+// from r, returning the total time of each application on each day,
+// indexed by date and then by application. Header rows are skipped
+// wherever they occur, and the times of repeated rows are summed, so
+// that several appended rollups can be read at once.
+func ReadDailyRollupCSV(r io.Reader) (map[string]map[string]time.Duration, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(rollupHeader)
+	totals := make(map[string]map[string]time.Duration)
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return totals, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if row[0] == rollupHeader[0] && row[1] == rollupHeader[1] && row[2] == rollupHeader[2] {
+			continue
+		}
+		seconds, err := strconv.ParseFloat(row[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seconds %q of %s on %s: %s", row[2], row[1], row[0], err)
+		}
+		if totals[row[0]] == nil {
+			totals[row[0]] = make(map[string]time.Duration)
+		}
+		totals[row[0]][row[1]] += time.Duration(seconds * float64(time.Second))
+	}
+}
@@ -0,0 +1,50 @@
+package thyme
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDailyRollupCSV(t *testing.T) {
+	// From 23:00 on March 2 to 00:50 on March 3.
+	snaps := spans(span{"main.go - GoLand", 90}, span{"Inbox - Gmail - Google Chrome", 20})
+	for _, snap := range snaps {
+		snap.Time = snap.Time.Add(14 * time.Hour)
+	}
+	var b bytes.Buffer
+	if err := WriteDailyRollupCSV(&b, snaps, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	const want = "date,app,seconds\n2026-03-02,GoLand,3600\n2026-03-03,GoLand,1800\n2026-03-03,Google Chrome,1200\n"
+	if got := b.String(); got != want {
+		t.Errorf("WriteDailyRollupCSV wrote %q, want %q", got, want)
+	}
+
+	// The rows of each day match the totals of the snapshots of the day,
+	// which is split at snaps[60], at midnight.
+	days := map[string]map[string]time.Duration{
+		"2026-03-02": AggregateByApp(snaps[:61]),
+		"2026-03-03": AggregateByApp(snaps[60:]),
+	}
+	got, err := ReadDailyRollupCSV(bytes.NewReader(b.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, days) {
+		t.Errorf("ReadDailyRollupCSV = %v, want %v", got, days)
+	}
+
+	// Appended rollups are summed.
+	got, err = ReadDailyRollupCSV(bytes.NewReader(append(b.Bytes(), b.Bytes()...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["2026-03-03"]["GoLand"] != time.Hour {
+		t.Errorf("reading a rollup appended to itself, GoLand on 2026-03-03 = %s, want 1h", got["2026-03-03"]["GoLand"])
+	}
+	if _, err := ReadDailyRollupCSV(bytes.NewBufferString("2026-03-03,GoLand,many\n")); err == nil {
+		t.Errorf("ReadDailyRollupCSV with invalid seconds returned no error")
+	}
+}
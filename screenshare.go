@@ -0,0 +1,34 @@
+package thyme
+
+import "strings"
+
+// PresentingCategory is the category of time spent sharing the screen.
+const PresentingCategory = "Presenting"
+
+// screenShareIndicators are substrings of the names of windows that are
+// shown only while the screen is being shared or recorded.
+var screenShareIndicators = []string{
+	"zoom share statusbar window",
+	"is sharing your screen",
+	"is sharing a window",
+	"Sharing control bar",
+}
+
+// RegisterScreenShareIndicator registers a substring of the name of a
+// window that is shown only while the screen is being shared.
+func RegisterScreenShareIndicator(name string) {
+	screenShareIndicators = append(screenShareIndicators, name)
+}
+
+// IsScreenSharing returns true if any window of the snapshot indicates
+// that the screen was being shared.
+func (s Snapshot) IsScreenSharing() bool {
+	for _, w := range s.Windows {
+		for _, indicator := range screenShareIndicators {
+			if strings.Contains(w.Name, indicator) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package thyme
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScreenSharing(t *testing.T) {
+	defer func(indicators []string) { screenShareIndicators = indicators }(screenShareIndicators)
+	RegisterScreenShareIndicator("is presenting")
+
+	snaps := spans(span{"main.go - GoLand", 15})
+	for _, snap := range snaps[10:15] {
+		snap.Windows = append(snap.Windows, &Window{ID: 2, Name: "Alice is presenting"})
+	}
+	if snaps[0].IsScreenSharing() || !snaps[10].IsScreenSharing() {
+		t.Errorf("IsScreenSharing = %t without the indicator and %t with it, want false and true", snaps[0].IsScreenSharing(), snaps[10].IsScreenSharing())
+	}
+	want := map[string]time.Duration{DevelopmentCategory: 10 * time.Minute, PresentingCategory: 5 * time.Minute}
+	if got := AggregateByCategory(snaps); !reflect.DeepEqual(got, want) {
+		t.Errorf("AggregateByCategory = %v, want %v", got, want)
+	}
+}
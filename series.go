@@ -0,0 +1,139 @@
+package thyme
+
+import "time"
+
+// CountPoint is a count at a point of a time series.
+type CountPoint struct {
+	T     time.Time
+	Count int
+}
+
+// VisibleCountSeries returns the maximum number of windows visible at
+// once in each bucket-sized interval of the recording. Buckets are
+// aligned to multiples of bucket (see time.Time.Truncate), and buckets
+// without any snapshots whose visible windows are known are omitted.
+func VisibleCountSeries(snaps []*Snapshot, bucket time.Duration) []CountPoint {
+	var series []CountPoint
+	for _, snap := range snaps {
+		ids := snap.visibleIDs()
+		if ids == nil {
+			continue
+		}
+		t := snap.Time.Truncate(bucket)
+		count := 0
+		for _, v := range ids {
+			if snap.window(v) != nil {
+				count++
+			}
+		}
+		if n := len(series); n > 0 && series[n-1].T.Equal(t) {
+			if count > series[n-1].Count {
+				series[n-1].Count = count
+			}
+			continue
+		}
+		series = append(series, CountPoint{T: t, Count: count})
+	}
+	return series
+}
+
+// PeakMultitasking returns the time of the snapshot with the most
+// visible non-system windows, along with their number. Of several such
+// snapshots, the earliest is returned. It returns the zero time and 0 if
+// no snapshot had a visible window.
+func PeakMultitasking(snaps []*Snapshot) (time.Time, int) {
+	var peak time.Time
+	max := 0
+	for _, snap := range snaps {
+		count := 0
+		seen := make(map[int64]bool)
+		for _, v := range snap.visibleIDs() {
+			if w := snap.window(v); w != nil && !w.IsSystem() && !seen[v] {
+				seen[v] = true
+				count++
+			}
+		}
+		if count > max {
+			peak, max = snap.Time, count
+		}
+	}
+	return peak, max
+}
+
+// Resample returns snapshots evenly spaced by cadence over the span of
+// snaps, each a copy of the most recent snapshot of snaps at or before
+// its time. This is useful for comparing recordings taken at different
+// intervals.
+func Resample(snaps []*Snapshot, cadence time.Duration) []*Snapshot {
+	if len(snaps) == 0 || cadence <= 0 {
+		return nil
+	}
+	var resampled []*Snapshot
+	end := snaps[len(snaps)-1].Time
+	i := 0
+	for t := snaps[0].Time; !t.After(end); t = t.Add(cadence) {
+		for i+1 < len(snaps) && !snaps[i+1].Time.After(t) {
+			i++
+		}
+		c := snaps[i].clone()
+		c.Time = t
+		resampled = append(resampled, c)
+	}
+	return resampled
+}
+
+// CategoryTimeSeries returns the time spent in each category of
+// applications (see AggregateByCategory) in each bucket-sized interval
+// of the recording, suitable for a stacked area chart. The first element
+// is the bucket containing the first snapshot (see time.Time.Truncate)
+// and every following element is the next bucket, so element i covers
+// the interval starting i buckets after the first.
+func CategoryTimeSeries(snaps []*Snapshot, bucket time.Duration) []map[string]time.Duration {
+	if len(snaps) == 0 || bucket <= 0 {
+		return nil
+	}
+	first := snaps[0].Time.Truncate(bucket)
+	n := int(snaps[len(snaps)-1].Time.Sub(first)/bucket) + 1
+	series := make([]map[string]time.Duration, n)
+	for i := range series {
+		series[i] = make(map[string]time.Duration)
+	}
+	forEachInterval(snaps, func(snap *Snapshot, start, end time.Time) {
+		c := snap.activeCategory()
+		if c == "" {
+			return
+		}
+		splitInterval(start, end, nextBucket(bucket), func(start, end time.Time) {
+			if i := int(start.Sub(first) / bucket); i < n {
+				series[i][c] += end.Sub(start)
+			}
+		})
+	})
+	return series
+}
+
+// Interval is a period of time.
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// gapFactor is how many times longer than expected the time between two
+// snapshots must be for RecordingGaps to report it.
+const gapFactor = 3
+
+// RecordingGaps returns the periods between consecutive snapshots that
+// are more than 3 times longer than expectedInterval, the interval at
+// which snapshots were meant to be taken. Such gaps usually mean the
+// recorder wasn't running and data was lost, as opposed to the user
+// being idle.
+func RecordingGaps(snaps []*Snapshot, expectedInterval time.Duration) []Interval {
+	var gaps []Interval
+	for i := 0; i+1 < len(snaps); i++ {
+		start, end := snaps[i].Time, snaps[i+1].Time
+		if end.Sub(start) > gapFactor*expectedInterval {
+			gaps = append(gaps, Interval{Start: start, End: end})
+		}
+	}
+	return gaps
+}
@@ -0,0 +1,90 @@
+package thyme
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVisibleCountSeries(t *testing.T) {
+	var snaps []*Snapshot
+	windows := []*Window{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}, {ID: 3, Name: "c"}, {ID: 4, Name: "d"}}
+	for i := 0; i < 20; i++ {
+		snap := &Snapshot{Time: testStart.Add(time.Duration(i) * time.Minute), Windows: windows, Active: 1, Visible: []int64{1}}
+		if i >= 10 {
+			snap.Visible = []int64{1, 2, 3, 4}
+		}
+		snaps = append(snaps, snap)
+	}
+	// A snapshot whose visible windows are unknown doesn't count.
+	snaps = append(snaps, &Snapshot{Time: testStart.Add(20 * time.Minute), Windows: windows, Active: 1})
+	want := []CountPoint{
+		{T: testStart, Count: 1},
+		{T: testStart.Add(5 * time.Minute), Count: 1},
+		{T: testStart.Add(10 * time.Minute), Count: 4},
+		{T: testStart.Add(15 * time.Minute), Count: 4},
+	}
+	if got := VisibleCountSeries(snaps, 5*time.Minute); !reflect.DeepEqual(got, want) {
+		t.Errorf("VisibleCountSeries = %+v, want %+v", got, want)
+	}
+}
+
+func TestResample(t *testing.T) {
+	snaps := session(3*time.Second, "main.go - GoLand", "main.go - GoLand", "Inbox - Gmail - Google Chrome", "Inbox - Gmail - Google Chrome")
+	got := Resample(snaps, time.Second)
+	if len(got) != 10 {
+		t.Fatalf("Resample returned %d snapshots, want 10", len(got))
+	}
+	for i, snap := range got {
+		if want := testStart.Add(time.Duration(i) * time.Second); !snap.Time.Equal(want) {
+			t.Errorf("snapshot %d is at %s, want %s", i, snap.Time, want)
+		}
+		// Each snapshot has the state of the last one at or before it.
+		prior := snaps[i/3]
+		if snap == prior || snap.Active != prior.Active || !reflect.DeepEqual(snap.Windows, prior.Windows) {
+			t.Errorf("snapshot %d = %s, want a copy of %s", i, snap.Print(), prior.Print())
+		}
+	}
+}
+
+func TestCategoryTimeSeries(t *testing.T) {
+	snaps := spans(span{"main.go - GoLand", 15}, span{"Inbox - Gmail - Google Chrome", 10}, span{"main.go - GoLand", 5})
+	want := []map[string]time.Duration{
+		{DevelopmentCategory: 10 * time.Minute},
+		{DevelopmentCategory: 5 * time.Minute, BrowserCategory: 5 * time.Minute},
+		{BrowserCategory: 5 * time.Minute, DevelopmentCategory: 5 * time.Minute},
+		// The bucket of the snapshot ending the recording.
+		{},
+	}
+	if got := CategoryTimeSeries(snaps, 10*time.Minute); !reflect.DeepEqual(got, want) {
+		t.Errorf("CategoryTimeSeries = %v, want %v", got, want)
+	}
+}
+
+func TestRecordingGaps(t *testing.T) {
+	snaps := session(5*time.Second, "main.go - GoLand", "main.go - GoLand", "main.go - GoLand")
+	// Slightly late snapshots aren't gaps.
+	snaps[2].Time = snaps[2].Time.Add(5 * time.Second)
+	resumed := &Snapshot{Time: snaps[2].Time.Add(10 * time.Minute)}
+	snaps = append(snaps, resumed, &Snapshot{Time: resumed.Time.Add(5 * time.Second)})
+	want := []Interval{{Start: snaps[2].Time, End: resumed.Time}}
+	if got := RecordingGaps(snaps, 5*time.Second); !reflect.DeepEqual(got, want) {
+		t.Errorf("RecordingGaps = %+v, want %+v", got, want)
+	}
+}
+
+func TestPeakMultitasking(t *testing.T) {
+	windows := []*Window{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}, {ID: 3, Name: "c"}, {ID: 4, Name: "d"}, {ID: 5, Name: "e"}, {ID: 6, Name: "Desktop"}}
+	visible := [][]int64{{1}, {1, 2}, {1, 2, 3, 4, 5}, {1, 2, 3}, {1, 2, 3, 4, 6, 6}}
+	var snaps []*Snapshot
+	for i, v := range visible {
+		snaps = append(snaps, &Snapshot{Time: testStart.Add(time.Duration(i) * time.Minute), Windows: windows, Active: 1, Visible: v})
+	}
+	// The system window and the repeated ID don't count.
+	if at, n := PeakMultitasking(snaps); !at.Equal(snaps[2].Time) || n != 5 {
+		t.Errorf("PeakMultitasking = %s, %d, want %s, 5", at, n, snaps[2].Time)
+	}
+	if at, n := PeakMultitasking(nil); !at.IsZero() || n != 0 {
+		t.Errorf("PeakMultitasking of no snapshots = %s, %d, want the zero time and 0", at, n)
+	}
+}
@@ -17,6 +17,7 @@ const maxNumberOfBars = 30
 // 2. A timeline of windows active, visible, and open
 // 3. A barchart of applications most often active, visible, and open
 func Stats(stream *Stream) error {
+	stream = &Stream{Snapshots: CollapsePhantoms(stream.Snapshots)}
 	tlFine := NewTimeline(stream, func(w *Window) string { return w.Name })
 	tlCoarse := NewTimeline(stream, appID)
 	agg := NewAggTime(stream, appID)
@@ -36,8 +37,12 @@ type AggTime struct {
 	Charts []*BarChart
 }
 
-// NewAggTime returns a new AggTime created from a Stream.
+// NewAggTime returns a new AggTime created from a Stream. labelFunc
+// determines the label of each window, as in NewTimeline.
 func NewAggTime(stream *Stream, labelFunc func(*Window) string) *AggTime {
+	if labelFunc == nil {
+		labelFunc = appID
+	}
 	n := strconv.Itoa(maxNumberOfBars)
 	active := NewBarChart("Active", "App", "Samples", "Top "+n+" active applications by time (multiplied by window count)")
 	visible := NewBarChart("Visible", "App", "Samples", "Top "+n+" visible applications by time (multiplied by window count)")
@@ -48,11 +53,15 @@ func NewAggTime(stream *Stream, labelFunc func(*Window) string) *AggTime {
 			windows[win.ID] = win
 		}
 
-		if win := windows[snap.Active]; win != nil {
-			active.Plus(labelFunc(windows[snap.Active]), 1)
+		if win := snap.ActiveWindow(); win != nil {
+			active.Plus(labelFunc(win), 1)
+		} else {
+			active.Plus(NoActiveWindow, 1)
 		}
-		for _, v := range snap.Visible {
-			visible.Plus(labelFunc(windows[v]), 1)
+		for _, v := range snap.visibleIDs() {
+			if win := windows[v]; win != nil {
+				visible.Plus(labelFunc(win), 1)
+			}
 		}
 		for _, win := range snap.Windows {
 			all.Plus(labelFunc(win), 1)
@@ -133,8 +142,12 @@ type Range struct {
 // Stream. labelFunc is used to determine the ID string to be used for
 // a given Window. If you're tracking events by app, this ID should
 // reflect the identity of the window's application. If you're
-// tracking events by window name, the ID should be the window name.
+// tracking events by window name, the ID should be the window name. If
+// labelFunc is nil, windows are labeled by application.
 func NewTimeline(stream *Stream, labelFunc func(*Window) string) *Timeline {
+	if labelFunc == nil {
+		labelFunc = appID
+	}
 	if len(stream.Snapshots) == 0 {
 		return nil
 	}
@@ -169,7 +182,7 @@ func NewTimeline(stream *Stream, labelFunc func(*Window) string) *Timeline {
 			prevRange.End = snap.Time
 		}
 		nextVisible := make(map[string]*Range)
-		for _, v := range snap.Visible {
+		for _, v := range snap.visibleIDs() {
 			var winLabel string
 			if win := windows[v]; win != nil {
 				winLabel = labelFunc(win)
@@ -223,7 +236,8 @@ type statsPage struct {
 // statsTmpl is the HTML template for the page rendered by the `Stats`
 // function.
 var statsTmpl = template.Must(template.New("").Funcs(map[string]interface{}{
-	"timeToJS": timeToJS,
+	"timeToJS":    timeToJS,
+	"displayName": DisplayName,
 }).Parse(`<html>
   <head>
 	<meta charset="utf-8">
@@ -257,7 +271,7 @@ var statsTmpl = template.Must(template.New("").Funcs(map[string]interface{}{
 		{{range .Rows.Active}}
 			[
 				"Active",
-				{{printf "%q" .Label}},
+				{{printf "%q" (displayName .Label)}},
 				{{timeToJS .Start}},
 				{{timeToJS .End}},
 			],
@@ -265,7 +279,7 @@ var statsTmpl = template.Must(template.New("").Funcs(map[string]interface{}{
 		{{range .Rows.Visible}}
 			[
 				"Visible",
-				{{printf "%q" .Label}},
+				{{printf "%q" (displayName .Label)}},
 				{{timeToJS .Start}},
 				{{timeToJS .End}},
 			],
@@ -273,7 +287,7 @@ var statsTmpl = template.Must(template.New("").Funcs(map[string]interface{}{
 		{{range .Rows.All}}
 			[
 				"All",
-				{{printf "%q" .Label}},
+				{{printf "%q" (displayName .Label)}},
 				{{timeToJS .Start}},
 				{{timeToJS .End}},
 			],
@@ -295,7 +309,7 @@ var statsTmpl = template.Must(template.New("").Funcs(map[string]interface{}{
       var data = google.visualization.arrayToDataTable([
         ['Application', 'Number of samples'],
 		{{range $chart.OrderedBars}}
-		[{{printf "%q" .Label}}, {{.Count}}],
+		[{{printf "%q" (displayName .Label)}}, {{.Count}}],
 		{{end}}
       ]);
 
@@ -336,7 +350,7 @@ var statsTmpl = template.Must(template.New("").Funcs(map[string]interface{}{
 		{{range .Rows.Active}}
 			[
 				"Active",
-				{{printf "%q" .Label}},
+				{{printf "%q" (displayName .Label)}},
 				{{timeToJS .Start}},
 				{{timeToJS .End}},
 			],
@@ -344,7 +358,7 @@ var statsTmpl = template.Must(template.New("").Funcs(map[string]interface{}{
 		{{range .Rows.Visible}}
 			[
 				"Visible",
-				{{printf "%q" .Label}},
+				{{printf "%q" (displayName .Label)}},
 				{{timeToJS .Start}},
 				{{timeToJS .End}},
 			],
@@ -352,7 +366,7 @@ var statsTmpl = template.Must(template.New("").Funcs(map[string]interface{}{
 		{{range .Rows.All}}
 			[
 				"All",
-				{{printf "%q" .Label}},
+				{{printf "%q" (displayName .Label)}},
 				{{timeToJS .Start}},
 				{{timeToJS .End}},
 			],
@@ -392,14 +406,15 @@ var statsTmpl = template.Must(template.New("").Funcs(map[string]interface{}{
 </html>`))
 
 // appID returns a string that identifies the application of the
-// window, w. It does so in best effort fashion. If the application
-// can't be determined, it returns the the name of the window.
+// window, w. It does so in best effort fashion, resolving registered
+// aliases (see RegisterAppAlias). If the application can't be
+// determined, it returns the the name of the window.
 func appID(w *Window) string {
 	if w == nil {
 		return "(nil)"
 	}
 	if w.Info().App != "" {
-		return w.Info().App
+		return canonicalApp(w.Info().App)
 	}
 	if w.Info().SubApp != "" {
 		return fmt.Sprintf("%s :: %s", w.Info().App, w.Info().SubApp)
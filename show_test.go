@@ -0,0 +1,35 @@
+package thyme
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportDisplayNames(t *testing.T) {
+	RegisterDisplayName("google-chrome-stable", "Chrome")
+	defer delete(displayNames, "google-chrome-stable")
+
+	snaps := session(time.Minute, "Inbox - Gmail - Google Chrome", "Inbox - Gmail - Google Chrome", "")
+	for _, snap := range snaps {
+		for _, w := range snap.Windows {
+			w.Class = "google-chrome-stable"
+		}
+		// A visible window that isn't listed is ignored.
+		snap.Visible = []int64{1, 7}
+	}
+	stream := &Stream{Snapshots: snaps}
+	groupKey := (*Window).GroupKey
+	page := &statsPage{Fine: NewTimeline(stream, nil), Coarse: NewTimeline(stream, groupKey), Agg: NewAggTime(stream, groupKey)}
+	if got := page.Agg.Charts[0].Series["google-chrome-stable"]; got != 2 {
+		t.Errorf("active chart has %d samples under the class, want 2", got)
+	}
+	var b bytes.Buffer
+	if err := statsTmpl.Execute(&b, page); err != nil {
+		t.Fatal(err)
+	}
+	if out := b.String(); !strings.Contains(out, `"Chrome"`) || strings.Contains(out, `"google-chrome-stable"`) {
+		t.Errorf("report doesn't label google-chrome-stable as Chrome")
+	}
+}
@@ -0,0 +1,221 @@
+package thyme
+
+import "time"
+
+// Switch is a change of the active application.
+type Switch struct {
+	At   time.Time
+	From string
+	To   string
+}
+
+// Switches returns the changes of the active application over snaps, in
+// order. Snapshots without an active application window are skipped, as
+// are idle snapshots, so briefly focusing the desktop or a system window
+// and then returning to the same application doesn't count as a switch.
+func Switches(snaps []*Snapshot) []Switch {
+	var switches []Switch
+	last := ""
+	for _, snap := range CollapsePhantoms(snaps) {
+		app := snap.activeApp()
+		if app == "" {
+			continue
+		}
+		if last != "" && app != last {
+			switches = append(switches, Switch{At: snap.Time, From: last, To: app})
+		}
+		last = app
+	}
+	return switches
+}
+
+// CountSwitches returns the number of times the active application
+// changes over snaps (see Switches).
+func CountSwitches(snaps []*Snapshot) int {
+	return len(Switches(snaps))
+}
+
+// SwitchCost estimates the time lost to context switching over snaps,
+// charging penalty for every switch counted by CountSwitches.
+func SwitchCost(snaps []*Snapshot, penalty time.Duration) time.Duration {
+	return time.Duration(CountSwitches(snaps)) * penalty
+}
+
+// EffectiveWorkTime returns the total time any application was active
+// (see TotalActive) minus the time lost to context switching (see
+// SwitchCost), or 0 if switching cost more than the active time.
+func EffectiveWorkTime(snaps []*Snapshot, penalty time.Duration) time.Duration {
+	if effective := TotalActive(snaps) - SwitchCost(snaps, penalty); effective > 0 {
+		return effective
+	}
+	return 0
+}
+
+// Interruption is a brief switch to a communication application after
+// which the user resumed what they were doing.
+type Interruption struct {
+	// At is when the interruption started.
+	At time.Time
+
+	// Into is the communication application that interrupted.
+	Into string
+
+	// Resumed is the application that was interrupted and resumed.
+	Resumed string
+}
+
+// Interruptions returns the focus blocks (see FocusBlocks) of
+// communication applications lasting at most maxInterruptLen that were
+// immediately preceded and followed by the same application, with no
+// idle time in between, as typically happens
+// when a notification draws the user's attention away.
+func Interruptions(snaps []*Snapshot, maxInterruptLen time.Duration) []Interruption {
+	var interruptions []Interruption
+	blocks := FocusBlocks(snaps)
+	for i := 1; i+1 < len(blocks); i++ {
+		b := blocks[i]
+		if b.Duration() > maxInterruptLen || appCategory(b.App) != CommunicationCategory {
+			continue
+		}
+		// The blocks must be contiguous: an interruption after or
+		// before idle time isn't one.
+		if blocks[i-1].App != blocks[i+1].App || !blocks[i-1].End.Equal(b.Start) || !b.End.Equal(blocks[i+1].Start) {
+			continue
+		}
+		interruptions = append(interruptions, Interruption{At: b.Start, Into: b.App, Resumed: blocks[i+1].App})
+	}
+	return interruptions
+}
+
+// SwitchBackTimes returns, for each application, how long the user was
+// away from it each time they left it and later returned, in order.
+func SwitchBackTimes(snaps []*Snapshot) map[string][]time.Duration {
+	away := make(map[string][]time.Duration)
+	left := make(map[string]time.Time)
+	for _, b := range FocusBlocks(snaps) {
+		if t, ok := left[b.App]; ok {
+			away[b.App] = append(away[b.App], b.Start.Sub(t))
+		}
+		left[b.App] = b.End
+	}
+	return away
+}
+
+// ReturnRate returns the fraction of the times the user left app that
+// they returned to it within the given time. It returns 0 if the user
+// never left app.
+func ReturnRate(snaps []*Snapshot, app string, within time.Duration) float64 {
+	left, returned := 0, 0
+	blocks := FocusBlocks(snaps)
+	for i, b := range blocks {
+		if b.App != app || i+1 == len(blocks) || blocks[i+1].App == app {
+			continue
+		}
+		left++
+		for _, next := range blocks[i+1:] {
+			if next.Start.Sub(b.End) > within {
+				break
+			}
+			if next.App == app {
+				returned++
+				break
+			}
+		}
+	}
+	if left == 0 {
+		return 0
+	}
+	return float64(returned) / float64(left)
+}
+
+// AttentionResidue returns, for each application, how often the user
+// briefly flicked back to it after leaving it: returned to it within the
+// given time of leaving it, and left it again within the given time.
+func AttentionResidue(snaps []*Snapshot, within time.Duration) map[string]int {
+	residue := make(map[string]int)
+	left := make(map[string]time.Time)
+	blocks := FocusBlocks(snaps)
+	for i, b := range blocks {
+		if t, ok := left[b.App]; ok && b.Start.Sub(t) <= within && b.Duration() <= within && i+1 < len(blocks) {
+			residue[b.App]++
+		}
+		left[b.App] = b.End
+	}
+	return residue
+}
+
+// SwitchesByHour returns the number of application switches (see
+// Switches) in each hour of the day in loc.
+func SwitchesByHour(snaps []*Snapshot, loc *time.Location) [24]int {
+	var counts [24]int
+	for _, sw := range Switches(snaps) {
+		counts[sw.At.In(loc).Hour()]++
+	}
+	return counts
+}
+
+// Transitions returns the number of switches (see Switches) from each
+// application to each other application, indexed by the application
+// switched from and then the application switched to.
+func Transitions(snaps []*Snapshot) map[string]map[string]int {
+	transitions := make(map[string]map[string]int)
+	for _, sw := range Switches(snaps) {
+		if transitions[sw.From] == nil {
+			transitions[sw.From] = make(map[string]int)
+		}
+		transitions[sw.From][sw.To]++
+	}
+	return transitions
+}
+
+// TopTransition returns the most frequent switch from one application to
+// another (see Transitions) and how often it happened. Ties are broken
+// by application names. It returns a zero count if there were no
+// switches.
+func TopTransition(snaps []*Snapshot) (from, to string, count int) {
+	for f, tos := range Transitions(snaps) {
+		for t, n := range tos {
+			if n > count || n == count && (f < from || f == from && t < to) {
+				from, to, count = f, t, n
+			}
+		}
+	}
+	return from, to, count
+}
+
+// Flutter is a period of rapid switching between applications.
+type Flutter struct {
+	Start, End time.Time
+	Switches   int
+}
+
+// DetectFlutter returns the periods, in order, during which the user
+// switched applications (see Switches) more than minSwitches times
+// within the given window of time, as when unable to settle on a task.
+// Overlapping periods are merged, and each period spans from its first
+// to its last switch.
+func DetectFlutter(snaps []*Snapshot, window time.Duration, minSwitches int) []Flutter {
+	switches := Switches(snaps)
+	var flutters []Flutter
+	first, last := -1, -1 // switches of the current flutter
+	j := 0
+	for i := range switches {
+		for j+1 < len(switches) && switches[j+1].At.Sub(switches[i].At) <= window {
+			j++
+		}
+		if j-i+1 <= minSwitches {
+			continue
+		}
+		if first == -1 || i > last {
+			if first != -1 {
+				flutters = append(flutters, Flutter{Start: switches[first].At, End: switches[last].At, Switches: last - first + 1})
+			}
+			first = i
+		}
+		last = j
+	}
+	if first != -1 {
+		flutters = append(flutters, Flutter{Start: switches[first].At, End: switches[last].At, Switches: last - first + 1})
+	}
+	return flutters
+}
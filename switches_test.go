@@ -0,0 +1,157 @@
+package thyme
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInterruptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		snaps []*Snapshot
+		want  []Interruption
+	}{{
+		name:  "contiguous",
+		snaps: spans(span{"main.go - GoLand", 10}, span{"general - Acme - Slack", 1}, span{"main.go - GoLand", 10}),
+		want:  []Interruption{{At: testStart.Add(10 * time.Minute), Into: "Slack", Resumed: "GoLand"}},
+	}, {
+		name:  "too long",
+		snaps: spans(span{"main.go - GoLand", 10}, span{"general - Acme - Slack", 5}, span{"main.go - GoLand", 10}),
+	}, {
+		name:  "not communication",
+		snaps: spans(span{"main.go - GoLand", 10}, span{"Inbox - Gmail - Google Chrome", 1}, span{"main.go - GoLand", 10}),
+	}, {
+		name:  "after a gap",
+		snaps: spans(span{"main.go - GoLand", 10}, span{"", 30}, span{"general - Acme - Slack", 1}, span{"main.go - GoLand", 10}),
+	}, {
+		name:  "before a gap",
+		snaps: spans(span{"main.go - GoLand", 10}, span{"general - Acme - Slack", 1}, span{"", 30}, span{"main.go - GoLand", 10}),
+	}}
+	for _, test := range tests {
+		if got := Interruptions(test.snaps, 2*time.Minute); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Interruptions = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSwitchCost(t *testing.T) {
+	var ss []span
+	for i := 0; i <= 10; i++ {
+		name := "main.go - GoLand"
+		if i%2 == 1 {
+			name = "general - Acme - Slack"
+		}
+		// Briefly focusing no window isn't a switch.
+		ss = append(ss, span{name, 3}, span{"", 1})
+	}
+	snaps := spans(ss...)
+	if n := CountSwitches(snaps); n != 10 {
+		t.Fatalf("CountSwitches = %d, want 10", n)
+	}
+	if got := SwitchCost(snaps, 30*time.Second); got != 5*time.Minute {
+		t.Errorf("SwitchCost with a 30s penalty = %s, want 5m", got)
+	}
+}
+
+func TestSwitchBackTimes(t *testing.T) {
+	snaps := spans(span{"main.go - GoLand", 10}, span{"Inbox - Gmail - Google Chrome", 5}, span{"main.go - GoLand", 10}, span{"general - Acme - Slack", 2}, span{"main.go - GoLand", 3})
+	// The user never returned to Chrome or Slack.
+	want := map[string][]time.Duration{"GoLand": {5 * time.Minute, 2 * time.Minute}}
+	if got := SwitchBackTimes(snaps); !reflect.DeepEqual(got, want) {
+		t.Errorf("SwitchBackTimes = %v, want %v", got, want)
+	}
+}
+
+func TestReturnRate(t *testing.T) {
+	// The user returns to GoLand after Slack, but not within 10 minutes
+	// of leaving it for Chrome, nor after leaving it at the end.
+	snaps := spans(span{"main.go - GoLand", 10}, span{"general - Acme - Slack", 2}, span{"main.go - GoLand", 10}, span{"Inbox - Gmail - Google Chrome", 20}, span{"general - Acme - Slack", 5}, span{"main.go - GoLand", 5}, span{"Inbox - Gmail - Google Chrome", 1})
+	if got := ReturnRate(snaps, "GoLand", 10*time.Minute); math.Abs(got-1.0/3) > 1e-9 {
+		t.Errorf("ReturnRate within 10m = %g, want 1/3", got)
+	}
+	if got := ReturnRate(snaps, "GoLand", time.Hour); math.Abs(got-2.0/3) > 1e-9 {
+		t.Errorf("ReturnRate within 1h = %g, want 2/3", got)
+	}
+	if got := ReturnRate(snaps, "Vim", time.Hour); got != 0 {
+		t.Errorf("ReturnRate of an unused app = %g, want 0", got)
+	}
+}
+
+func TestSwitchesByHour(t *testing.T) {
+	// Switches at 9:50, 9:55, 10:05, and 10:35 UTC.
+	snaps := spans(span{"main.go - GoLand", 50}, span{"general - Acme - Slack", 5}, span{"main.go - GoLand", 10}, span{"Inbox - Gmail - Google Chrome", 30}, span{"main.go - GoLand", 5})
+	var want [24]int
+	want[9], want[10] = 2, 2
+	if got := SwitchesByHour(snaps, time.UTC); got != want {
+		t.Errorf("SwitchesByHour in UTC = %v, want %v", got, want)
+	}
+	want = [24]int{}
+	want[10], want[11] = 2, 2
+	if got := SwitchesByHour(snaps, time.FixedZone("UTC+1", 60*60)); got != want {
+		t.Errorf("SwitchesByHour in UTC+1 = %v, want %v", got, want)
+	}
+}
+
+func TestTopTransition(t *testing.T) {
+	var ss []span
+	for i := 0; i < 3; i++ {
+		ss = append(ss, span{"main.go - GoLand", 5}, span{"Inbox - Gmail - Google Chrome", 5})
+	}
+	// GoLand → Chrome happens 3 times, Chrome → GoLand twice, and
+	// Chrome → Slack and Slack → GoLand once.
+	ss = append(ss, span{"general - Acme - Slack", 1}, span{"main.go - GoLand", 1})
+	if from, to, count := TopTransition(spans(ss...)); from != "GoLand" || to != "Google Chrome" || count != 3 {
+		t.Errorf("TopTransition = %s → %s (%d), want GoLand → Google Chrome (3)", from, to, count)
+	}
+	if _, _, count := TopTransition(spans(span{"main.go - GoLand", 5})); count != 0 {
+		t.Errorf("TopTransition without switches has count %d, want 0", count)
+	}
+}
+
+func TestAttentionResidue(t *testing.T) {
+	snaps := spans(span{"main.go - GoLand", 20}, span{"Inbox - Gmail - Google Chrome", 10}, span{"main.go - GoLand", 1}, span{"Inbox - Gmail - Google Chrome", 20})
+	// Returning to Chrome for 20 minutes isn't a brief return.
+	want := map[string]int{"GoLand": 1}
+	if got := AttentionResidue(snaps, 15*time.Minute); !reflect.DeepEqual(got, want) {
+		t.Errorf("AttentionResidue = %v, want %v", got, want)
+	}
+	if got := AttentionResidue(snaps, 5*time.Minute); len(got) != 0 {
+		t.Errorf("AttentionResidue within 5m = %v, want none", got)
+	}
+}
+
+func TestDetectFlutter(t *testing.T) {
+	at := func(m int) time.Time { return testStart.Add(time.Duration(m) * time.Minute) }
+	// Switches every minute from 9:30 to 9:35, and two more, at 10:05 and
+	// 10:06, that aren't enough to flutter.
+	snaps := spans(span{"main.go - GoLand", 30},
+		span{"general - Acme - Slack", 1}, span{"Inbox - Gmail - Google Chrome", 1}, span{"general - Acme - Slack", 1}, span{"Inbox - Gmail - Google Chrome", 1}, span{"general - Acme - Slack", 1},
+		span{"main.go - GoLand", 30}, span{"Inbox - Gmail - Google Chrome", 1}, span{"main.go - GoLand", 10})
+	want := []Flutter{{Start: at(30), End: at(35), Switches: 6}}
+	if got := DetectFlutter(snaps, 5*time.Minute, 4); !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectFlutter = %+v, want %+v", got, want)
+	}
+	if got := DetectFlutter(snaps, 5*time.Minute, 6); got != nil {
+		t.Errorf("DetectFlutter with more than 6 switches = %+v, want none", got)
+	}
+}
+
+func TestEffectiveWorkTime(t *testing.T) {
+	// An hour of active time with 2 switches.
+	snaps := spans(span{"main.go - GoLand", 30}, span{"general - Acme - Slack", 5}, span{"main.go - GoLand", 25})
+	tests := []struct {
+		penalty, want time.Duration
+	}{
+		{0, time.Hour},
+		{5 * time.Minute, 50 * time.Minute},
+		{30 * time.Minute, 0},
+		{time.Hour, 0},
+	}
+	for _, test := range tests {
+		if got := EffectiveWorkTime(snaps, test.penalty); got != test.want {
+			t.Errorf("EffectiveWorkTime with a %s penalty = %s, want %s", test.penalty, got, test.want)
+		}
+	}
+}
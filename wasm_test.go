@@ -0,0 +1,22 @@
+//go:build js
+// +build js
+
+package thyme
+
+import (
+	"testing"
+	"time"
+)
+
+// TestWebAssembly checks that parsing and aggregation work when built
+// for WebAssembly (see release.sh), where the trackers aren't available.
+func TestWebAssembly(t *testing.T) {
+	w := &Window{Name: "Inbox - Gmail - Google Chrome"}
+	if got, want := *w.Info(), (Winfo{App: "Google Chrome", SubApp: "Gmail", Title: "Inbox"}); got != want {
+		t.Errorf("Info = %+v, want %+v", got, want)
+	}
+	totals := AggregateByApp(spans(span{"main.go - GoLand", 10}, span{"Inbox - Gmail - Google Chrome", 5}))
+	if totals["GoLand"] != 10*time.Minute || totals["Google Chrome"] != 5*time.Minute {
+		t.Errorf("AggregateByApp = %v, want 10m of GoLand and 5m of Google Chrome", totals)
+	}
+}
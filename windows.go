@@ -1,3 +1,4 @@
+//go:build windows
 // +build windows
 
 package thyme
@@ -76,7 +77,10 @@ func windowsIgnore(title string) bool {
 
 func (t *WindowsTracker) Snap() (snap *Snapshot, err error) {
 	var allWindows []*Window
-	var visible []int64
+	// An empty (rather than nil) Visible tells a snapshot in which no
+	// window was visible apart from one whose visible windows are
+	// unknown.
+	visible := []int64{}
 	var active int64
 
 	var cbId uintptr = 888
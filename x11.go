@@ -0,0 +1,289 @@
+package thyme
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseX11 parses a Snapshot from the output of the command-line
+// utilities the Linux tracker runs: `wmctrl -l` (wmctrlOut), `xdotool
+// getactivewindow` (activeOut), and `wmctrl -d` (desktopOut). It is
+// useful to debug window parsing offline. The returned snapshot has no
+// visible windows or time, as those aren't determined by these commands.
+//
+// Some applications (notably Java/AWT ones) report the same ID for
+// several of their windows. Such windows are given unique IDs (see
+// dedupeWindowIDs), and if activeOut has a second line with the name of
+// the active window (as printed by `xdotool getwindowname`), it is used
+// to pick which of them is active; otherwise, the first of them listed
+// is.
+func ParseX11(wmctrlOut, activeOut, desktopOut string) (*Snapshot, error) {
+	var windows []*Window
+	{
+		lines := strings.Split(wmctrlOut, "\n")
+		for _, line := range lines {
+			fields := strings.Fields(line)
+			if len(fields) < 4 {
+				continue
+			}
+			id_, desktop_, name := fields[0], fields[1], strings.Join(fields[3:], " ")
+			id, err := strconv.ParseInt(id_, 0, 64)
+			if err != nil {
+				return nil, err
+			}
+			desktop, err := strconv.ParseInt(desktop_, 0, 64)
+			if err != nil {
+				return nil, err
+			}
+			w := Window{ID: id, Desktop: desktop, Name: name}
+			if !w.IsSystem() {
+				windows = append(windows, &w)
+			}
+		}
+	}
+
+	var currentDesktop int64
+	{
+		lines := strings.Split(desktopOut, "\n")
+		for _, line := range lines {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			id_, mode := fields[0], fields[1]
+			id, err := strconv.ParseInt(id_, 0, 64)
+			if err != nil {
+				return nil, err
+			}
+			if "*" == mode {
+				currentDesktop = id
+			}
+		}
+	}
+
+	var active int64
+	var activeName string
+	{
+		lines := strings.SplitN(strings.TrimSpace(activeOut), "\n", 2)
+		id, err := strconv.ParseInt(strings.TrimSpace(lines[0]), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		active = id
+		if len(lines) > 1 {
+			activeName = strings.TrimSpace(lines[1])
+		}
+	}
+	active = dedupeWindowIDs(windows, active, activeName)
+
+	return &Snapshot{Windows: windows, Active: active, Desktop: currentDesktop}, nil
+}
+
+// dedupeWindowIDs gives a unique ID to each window whose ID is shared
+// with a window of a different name, by setting bits above the 32 bits
+// used by X11 window IDs to a hash of the window name. The synthesized
+// ID of a window thus stays the same from one snapshot to the next as
+// long as its ID is shared, regardless of the order in which windows are
+// listed, but it reverts to the X11 ID once no other window shares it;
+// use xid to compare windows across snapshots. Windows with the same ID
+// and name are left as they are. It returns the ID of the active window:
+// the window with the active ID and activeName as its name, or, if
+// activeName is empty or no such window exists, the first window with
+// the active ID.
+func dedupeWindowIDs(windows []*Window, active int64, activeName string) int64 {
+	names := make(map[int64]map[string]bool)
+	for _, w := range windows {
+		if names[w.ID] == nil {
+			names[w.ID] = make(map[string]bool)
+		}
+		names[w.ID][w.Name] = true
+	}
+
+	offsets := make(map[int64]map[string]int64)
+	for id, group := range names {
+		if len(group) < 2 {
+			continue
+		}
+		sorted := make([]string, 0, len(group))
+		for name := range group {
+			sorted = append(sorted, name)
+		}
+		sort.Strings(sorted)
+		offsets[id] = make(map[string]int64, len(group))
+		used := make(map[int64]bool, len(group))
+		for _, name := range sorted {
+			h := fnv.New32a()
+			io.WriteString(h, name)
+			n := int64(h.Sum32()&0x7fffffff) | 1
+			for used[n] {
+				// Resolve hash collisions deterministically.
+				n = (n + 2) & 0x7fffffff
+			}
+			used[n] = true
+			offsets[id][name] = n
+		}
+	}
+
+	newActive, named := active, false
+	for _, w := range windows {
+		id := w.ID
+		w.ID = id | offsets[id][w.Name]<<32
+		switch {
+		case id != active || named:
+		case activeName != "" && w.Name == activeName:
+			newActive, named = w.ID, true
+		case newActive == active:
+			// Fall back to the first window with the active ID.
+			newActive = w.ID
+		}
+	}
+	return newActive
+}
+
+// xid returns the X11 window ID of a window ID that may have been made
+// unique by dedupeWindowIDs.
+func xid(id int64) int64 {
+	return id & 0xffffffff
+}
+
+// maxTreeDepth is the number of ancestors of the active window that are
+// searched for a listed window.
+const maxTreeDepth = 4
+
+var (
+	treeParentRx = regexp.MustCompile(`Parent window id: (0x[0-9a-fA-F]+)`)
+	treeChildRx  = regexp.MustCompile(`(?m)^\s+(0x[0-9a-fA-F]+) `)
+)
+
+// parseWindowTree parses the parent and descendants of a window from
+// the output of `xwininfo -tree`.
+func parseWindowTree(out string) (parent int64, children []int64) {
+	if m := treeParentRx.FindStringSubmatch(out); m != nil {
+		parent, _ = strconv.ParseInt(m[1], 0, 64)
+	}
+	for _, m := range treeChildRx.FindAllStringSubmatch(out, -1) {
+		if id, err := strconv.ParseInt(m[1], 0, 64); err == nil {
+			children = append(children, id)
+		}
+	}
+	return parent, children
+}
+
+// reconcileActive makes a listed window the active window if it is the
+// parent or a descendant of the active window (i.e., the active window
+// is the client of a listed frame window, or the frame of a listed
+// client window). It returns true if the active window was changed.
+func (s *Snapshot) reconcileActive(parent int64, children []int64) bool {
+	for _, id := range append(children, parent) {
+		if s.window(id) != nil {
+			s.Active = id
+			return true
+		}
+	}
+	return false
+}
+
+// isVisible checks if the window is visible in the current viewport.
+// x and y are assumed to be relative to the current viewport (i.e.,
+// (0, 0) is the coordinate of the top-left corner of the current
+// viewport.
+func isVisible(x, y, w, h, viewHeight, viewWidth int) bool {
+	return (0 <= x && x < viewWidth && 0 <= y && y < viewHeight) ||
+		(0 <= x+w && x+w < viewWidth && 0 <= y && y < viewHeight) ||
+		(0 <= x && x < viewWidth && 0 <= y+h && y+h < viewHeight) ||
+		(0 <= x+w && x+w < viewWidth && 0 <= y+h && y+h < viewHeight)
+}
+
+var (
+	dimRx = regexp.MustCompile(`dimensions:\s+([0-9]+)x([0-9]+)\s+pixels`)
+	xRx   = regexp.MustCompile(`Absolute upper\-left X:\s+(\-?[0-9]+)`)
+	yRx   = regexp.MustCompile(`Absolute upper\-left Y:\s+(\-?[0-9]+)`)
+	wRx   = regexp.MustCompile(`Width:\s+([0-9]+)`)
+	hRx   = regexp.MustCompile(`Height:\s+([0-9]+)`)
+)
+
+var xrandrOutputRx = regexp.MustCompile(`(?m)^(\S+) connected (primary )?([0-9]+)x([0-9]+)\+([0-9]+)\+([0-9]+)`)
+
+// parseXrandr parses the connected, active outputs from the output of
+// `xrandr --query`.
+func parseXrandr(out string) []Output {
+	var outputs []Output
+	for _, m := range xrandrOutputRx.FindAllStringSubmatch(out, -1) {
+		var dims [4]int
+		for i := range dims {
+			dims[i], _ = strconv.Atoi(m[3+i])
+		}
+		outputs = append(outputs, Output{
+			Name:    m[1],
+			Rect:    Rect{X: dims[2], Y: dims[3], Width: dims[0], Height: dims[1]},
+			Primary: m[2] != "",
+		})
+	}
+	return outputs
+}
+
+var wmClassRx = regexp.MustCompile(`WM_CLASS\(STRING\) = "([^"]*)"(?:, "([^"]*)")?`)
+
+// parseWMClass parses the class of a window from the output of `xprop
+// WM_CLASS`, preferring the class name over the instance name. It
+// returns "" if the window has no class.
+func parseWMClass(out string) string {
+	m := wmClassRx.FindStringSubmatch(out)
+	if m == nil {
+		return ""
+	}
+	if m[2] != "" {
+		return m[2]
+	}
+	return m[1]
+}
+
+var opacityRx = regexp.MustCompile(`_NET_WM_WINDOW_OPACITY\(CARDINAL\) = ([0-9]+)`)
+
+// parseOpacity parses the opacity of a window from the output of `xprop
+// _NET_WM_WINDOW_OPACITY`, scaling it to [0, 1]. Windows without the
+// property are opaque.
+func parseOpacity(out string) float64 {
+	m := opacityRx.FindStringSubmatch(out)
+	if m == nil {
+		return 1
+	}
+	n, err := strconv.ParseUint(m[1], 10, 32)
+	if err != nil {
+		return 1
+	}
+	return float64(n) / 0xffffffff
+}
+
+var windowTypeRx = regexp.MustCompile(`_NET_WM_WINDOW_TYPE\(ATOM\) = _NET_WM_WINDOW_TYPE_([A-Z_]+)`)
+
+// parseWindowType parses the type of a window (e.g., "normal" or
+// "dialog") from the output of `xprop _NET_WM_WINDOW_TYPE`, using the
+// first, most preferred, type listed. It returns "" if the window has no
+// type.
+func parseWindowType(out string) string {
+	m := windowTypeRx.FindStringSubmatch(out)
+	if m == nil {
+		return ""
+	}
+	return strings.ToLower(m[1])
+}
+
+// parseWinDim parses window dimension info from the output of `xwininfo`
+func parseWinDim(rx *regexp.Regexp, out string, varname string) (int, error) {
+	if matches := rx.FindStringSubmatch(out); len(matches) == 2 {
+		n, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return 0, err
+		}
+		return n, nil
+	} else {
+		return 0, fmt.Errorf("could not parse window %s from output %s", varname, out)
+	}
+
+}
@@ -0,0 +1,185 @@
+package thyme
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	testWmctrlOut = `0x01e00003 -1 host Top Panel
+0x03a00004  0 host main.go - thyme - Visual Studio Code
+0x04200001  1 host Inbox - Gmail - Google Chrome
+0x05000007  0 host Terminal
+`
+	testDesktopOut = `0  - DG: 3840x1080  VP: 0,0  WA: 0,0 3840x1080  one
+1  * DG: 3840x1080  VP: 0,0  WA: 0,0 3840x1080  two
+`
+)
+
+func TestParseX11(t *testing.T) {
+	snap, err := ParseX11(testWmctrlOut, "69206017\n", testDesktopOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Snapshot{
+		Windows: []*Window{
+			{ID: 0x01e00003, Desktop: -1, Name: "Top Panel"},
+			{ID: 0x03a00004, Desktop: 0, Name: "main.go - thyme - Visual Studio Code"},
+			{ID: 0x04200001, Desktop: 1, Name: "Inbox - Gmail - Google Chrome"},
+			{ID: 0x05000007, Desktop: 0, Name: "Terminal"},
+		},
+		Active:  0x04200001,
+		Desktop: 1,
+	}
+	if !reflect.DeepEqual(snap, want) {
+		t.Errorf("ParseX11 = %s, want %s", snap.Print(), want.Print())
+	}
+}
+
+func TestParseX11SharedIDs(t *testing.T) {
+	const shared = 0x06400001
+	parse := func(wmctrlOut string) map[string]int64 {
+		snap, err := ParseX11(wmctrlOut, "104857601\nSettings\n", "0  * DG: 1920x1080\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make(map[string]int64)
+		for _, w := range snap.Windows {
+			if xid(w.ID) != shared {
+				t.Errorf("window %q has X11 ID %#x, want %#x", w.Name, xid(w.ID), shared)
+			}
+			ids[w.Name] = w.ID
+		}
+		if snap.Active != ids["Settings"] {
+			t.Errorf("active window is %#x, want %#x", snap.Active, ids["Settings"])
+		}
+		return ids
+	}
+	a := parse("0x06400001 0 host Main\n0x06400001 0 host Settings\n")
+	b := parse("0x06400001 0 host Settings\n0x06400001 0 host Main\n0x06400001 0 host Search\n")
+	if a["Main"] == a["Settings"] {
+		t.Errorf("windows sharing an ID weren't given unique IDs: %#x", a["Main"])
+	}
+	for name, id := range a {
+		if b[name] != id {
+			t.Errorf("ID of %q changed from %#x to %#x when windows were reordered", name, id, b[name])
+		}
+	}
+}
+
+func TestReconcileActive(t *testing.T) {
+	const tree = `
+xwininfo: Window id: 0x4200005 "Inbox - Gmail - Google Chrome"
+
+  Root window id: 0x1a0 (the root window) (has no name)
+  Parent window id: 0x1000123 (has no name)
+     1 child:
+     0x4200006 (has no name): ()  1x1+-1+-1  +-1+-1
+`
+	tests := []struct {
+		listed, active, want int64
+	}{
+		// The active window is the client of a listed frame.
+		{listed: 0x1000123, active: 0x4200005, want: 0x1000123},
+		// The active window is the frame of a listed client.
+		{listed: 0x4200006, active: 0x4200005, want: 0x4200006},
+		// Neither is listed.
+		{listed: 0x5000000, active: 0x4200005, want: 0x4200005},
+	}
+	for _, test := range tests {
+		snap := &Snapshot{Windows: []*Window{{ID: test.listed, Name: "Inbox - Gmail - Google Chrome"}}, Active: test.active}
+		parent, children := parseWindowTree(tree)
+		changed := snap.reconcileActive(parent, children)
+		if snap.Active != test.want || changed != (test.want != test.active) {
+			t.Errorf("with window %#x listed, active window %#x reconciled to %#x (changed: %t), want %#x", test.listed, test.active, snap.Active, changed, test.want)
+		}
+	}
+}
+
+func TestParseX11SharedActiveID(t *testing.T) {
+	const wmctrlOut = "0x06400001 0 host Main\n0x06400001 0 host Settings\n0x05000007 0 host Terminal\n"
+	tests := []struct {
+		activeOut, want string
+	}{
+		{"104857601\nSettings\n", "Settings"},
+		// Without a matching name, the first window with the active ID
+		// is active.
+		{"104857601\n", "Main"},
+		{"104857601\n\n", "Main"},
+		{"104857601\nSearch\n", "Main"},
+		{"83886087\nSearch\n", "Terminal"},
+	}
+	for _, test := range tests {
+		snap, err := ParseX11(wmctrlOut, test.activeOut, "0  * DG: 1920x1080\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w := snap.window(snap.Active); w == nil || w.Name != test.want {
+			t.Errorf("with active output %q, active window is %+v, want %q", test.activeOut, w, test.want)
+		}
+	}
+}
+
+func TestParseOpacity(t *testing.T) {
+	tests := []struct {
+		out  string
+		want float64
+	}{
+		{"_NET_WM_WINDOW_OPACITY(CARDINAL) = 4294967295\n", 1},
+		{"_NET_WM_WINDOW_OPACITY(CARDINAL) = 2147483647\n", 0.5},
+		{"_NET_WM_WINDOW_OPACITY(CARDINAL) = 0\n", 0},
+		// Windows that don't set their opacity are opaque.
+		{"_NET_WM_WINDOW_OPACITY:  not found.\n", 1},
+	}
+	for _, test := range tests {
+		if got := parseOpacity(test.out); math.Abs(got-test.want) > 1e-6 {
+			t.Errorf("parseOpacity(%q) = %g, want %g", test.out, got, test.want)
+		}
+	}
+}
+
+func TestIsTranslucent(t *testing.T) {
+	for opacity, want := range map[float64]bool{0: false, 0.5: true, 1: false} {
+		if got := (&Window{Opacity: opacity}).IsTranslucent(); got != want {
+			t.Errorf("IsTranslucent with opacity %g = %t, want %t", opacity, got, want)
+		}
+	}
+}
+
+func TestParseWindowType(t *testing.T) {
+	const xpropOut = `WM_CLASS(STRING) = "gimp", "Gimp"
+_NET_WM_WINDOW_OPACITY:  not found.
+_NET_WM_WINDOW_TYPE(ATOM) = _NET_WM_WINDOW_TYPE_DIALOG, _NET_WM_WINDOW_TYPE_NORMAL
+`
+	tests := []struct {
+		out, want string
+	}{
+		{xpropOut, "dialog"},
+		{"_NET_WM_WINDOW_TYPE(ATOM) = _NET_WM_WINDOW_TYPE_NORMAL\n", NormalWindowType},
+		{"_NET_WM_WINDOW_TYPE:  not found.\n", ""},
+	}
+	for _, test := range tests {
+		if got := parseWindowType(test.out); got != test.want {
+			t.Errorf("parseWindowType(%q) = %q, want %q", test.out, got, test.want)
+		}
+	}
+
+	snaps := spans(span{"main.go - GoLand", 10}, span{"Export Image - GIMP", 5})
+	for _, snap := range snaps[:15] {
+		snap.Windows[0].Type = NormalWindowType
+		if snap.Windows[0].Name == "Export Image - GIMP" {
+			snap.Windows[0].Type = parseWindowType(xpropOut)
+		}
+	}
+	if got := AggregateByApp(snaps); got["GIMP"] != 5*time.Minute {
+		t.Errorf("without SystemNonNormalTypes, AggregateByApp = %v, want 5m of GIMP", got)
+	}
+	SystemNonNormalTypes = true
+	defer func() { SystemNonNormalTypes = false }()
+	want := map[string]time.Duration{"GoLand": 10 * time.Minute}
+	if got := AggregateByApp(snaps); !reflect.DeepEqual(got, want) {
+		t.Errorf("with SystemNonNormalTypes, AggregateByApp = %v, want %v", got, want)
+	}
+}